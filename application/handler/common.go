@@ -1,5 +1,153 @@
 package handler
 
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/aggregate"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+// perPage 一覧APIの1ページあたりの件数
+const perPage = 20
+
 type BusinessErrorResponse struct {
 	Message string `json:"message"`
 }
+
+// resolvePage はページ番号が未指定の場合に1ページ目として扱います
+func resolvePage(page *int64) int64 {
+	if page == nil {
+		return 1
+	}
+	return *page
+}
+
+// pageOffset はページ番号から取得開始位置を算出します
+func pageOffset(page int64) int {
+	return int((page - 1) * perPage)
+}
+
+// calcMaxPage は総件数から最大ページ数を算出します
+func calcMaxPage(total int) int64 {
+	if total == 0 {
+		return 1
+	}
+	return int64((total + perPage - 1) / perPage)
+}
+
+// newErrorPayload はレスポンス用のエラーモデルを生成します
+func newErrorPayload(code types.Code, message string) *models.Error {
+	return &models.Error{
+		Code:      int64(code.HTTPStatus()),
+		ErrorCode: string(code),
+		Message:   util.Ptr(message),
+	}
+}
+
+// asBusinessError はエラーの原因が BasicBusinessError の場合にそれを取り出します
+func asBusinessError(err error) (*types.BasicBusinessError, bool) {
+	var businessErr *types.BasicBusinessError
+	if !eris.As(err, &businessErr) {
+		return nil, false
+	}
+	return businessErr, true
+}
+
+// asForbiddenError はエラーの原因が ForbiddenError の場合にそれを取り出します
+// 書き込み系のハンドラでの権限の確認のほか、ent の privacy ルールで拒否された場合も ForbiddenError になります
+func asForbiddenError(err error) (*types.ForbiddenError, bool) {
+	var forbiddenErr *types.ForbiddenError
+	if !eris.As(err, &forbiddenErr) {
+		return nil, false
+	}
+	return forbiddenErr, true
+}
+
+// authorizeRequestTenant はリクエストの処理対象のテナントに対する操作の権限を確認します
+// テナントの範囲に制限されていない場合は確認しません（ent の privacy ルールで確認されます）
+// テナントを解決できていない場合は tenancy.ErrTenantRequired を返します
+func authorizeRequestTenant(ctx context.Context, authorizer authz.IAuthorizer, action authz.Action) error {
+	tenantID, ok, err := tenancy.Resolve(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if !ok {
+		return nil
+	}
+	return authorizer.Authorize(ctx, tenantID, action)
+}
+
+// isProductNotFound はエラーの原因が product が存在しないことによるものかを判定します
+// products テーブルを直接更新する場合は ent.NotFoundError、イベントソーシングの場合は aggregate.ErrProductNotFound になります
+func isProductNotFound(err error) bool {
+	return ent.IsNotFound(err) || eris.Is(err, aggregate.ErrProductNotFound)
+}
+
+// toProductPropertiesModel はAPIのpropertiesをドメインモデルに変換します
+func toProductPropertiesModel(p *models.ProductProperties) model.ProductProperties {
+	if p == nil {
+		return model.ProductProperties{}
+	}
+	return model.ProductProperties{
+		Size:      p.Size,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Color:     p.Color,
+	}
+}
+
+// toProductResponse はentのproductをレスポンス用のモデルに変換します
+func toProductResponse(p *ent.Product) *models.Product {
+	properties := &models.ProductProperties{}
+	if p.Properties != nil {
+		properties.Size = p.Properties.Size
+		properties.Latitude = p.Properties.Latitude
+		properties.Longitude = p.Properties.Longitude
+		properties.Color = p.Properties.Color
+	}
+
+	return &models.Product{
+		ID:         util.Ptr(strfmt.UUID(p.ID.String())),
+		TenantID:   util.Ptr(strfmt.UUID(p.TenantID.String())),
+		CategoryID: util.Ptr(strfmt.UUID(p.CategoryID.String())),
+		Name:       util.Ptr(p.Name),
+		Price:      util.Ptr(p.Price),
+		Properties: properties,
+		ListedAt:   util.Ptr(strfmt.DateTime(p.ListedAt)),
+		Version:    util.Ptr(p.Version),
+	}
+}
+
+// toUserResponse はentのuserをレスポンス用のモデルに変換します
+func toUserResponse(u *ent.User) *models.User {
+	return &models.User{
+		ID:   util.Ptr(strfmt.UUID(u.ID.String())),
+		Name: util.Ptr(u.Name),
+	}
+}
+
+// toTenantResponse はentのtenantをレスポンス用のモデルに変換します
+func toTenantResponse(t *ent.Tenant) *models.Tenant {
+	return &models.Tenant{
+		ID:      util.Ptr(strfmt.UUID(t.ID.String())),
+		OwnerID: util.Ptr(strfmt.UUID(t.OwnerID.String())),
+		Name:    util.Ptr(t.Name),
+		Version: util.Ptr(t.Version),
+	}
+}
+
+// toCategoryResponse はentのcategoryをレスポンス用のモデルに変換します
+func toCategoryResponse(c *ent.Category) *models.Category {
+	return &models.Category{
+		ID:   util.Ptr(strfmt.UUID(c.ID.String())),
+		Name: util.Ptr(c.Name),
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/category"
+	"github.com/t-kuni/cqrs-example/ent/product"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/categories"
+)
+
+// DeleteCategories カテゴリを削除するハンドラです
+type DeleteCategories struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewDeleteCategories(conn db.IConnector, logger system.ILogger) (*DeleteCategories, error) {
+	return &DeleteCategories{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h DeleteCategories) Main(params categories.DeleteCategoriesParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	var notFound bool
+	var referenced bool
+	err = h.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		exists, err := existsCategory(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !exists {
+			notFound = true
+			return nil
+		}
+
+		referenced, err = existsProductsByCategory(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if referenced {
+			return nil
+		}
+
+		return deleteCategory(ctx, tx, id)
+	})
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	if notFound {
+		return categories.NewDeleteCategoriesNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "category not found"))
+	}
+	if referenced {
+		return categories.NewDeleteCategoriesConflict().WithPayload(newErrorPayload(types.CodeConflict, "category has products"))
+	}
+
+	return categories.NewDeleteCategoriesNoContent()
+}
+
+func existsCategory(ctx context.Context, client *ent.Client, id uuid.UUID) (bool, error) {
+	return client.Category.Query().Where(category.ID(id)).Exist(ctx)
+}
+
+// existsProductsByCategory はテナントを跨いで product の有無を確認します
+func existsProductsByCategory(ctx context.Context, client *ent.Client, categoryID uuid.UUID) (bool, error) {
+	return client.Product.Query().Where(product.CategoryID(categoryID)).Exist(tenancy.WithoutTenantScope(ctx))
+}
+
+func deleteCategory(ctx context.Context, client *ent.Client, id uuid.UUID) error {
+	return client.Category.DeleteOneID(id).Exec(ctx)
+}
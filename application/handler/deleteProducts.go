@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+)
+
+// DeleteProducts productを削除するハンドラです
+type DeleteProducts struct {
+	CommandBus command.IBus
+	Authorizer authz.IAuthorizer
+	Logger     system.ILogger
+}
+
+func NewDeleteProducts(commandBus command.IBus, authorizer authz.IAuthorizer, logger system.ILogger) (*DeleteProducts, error) {
+	return &DeleteProducts{
+		CommandBus: commandBus,
+		Authorizer: authorizer,
+		Logger:     logger,
+	}, nil
+}
+
+func (h DeleteProducts) Main(params products.DeleteProductsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	err = authorizeRequestTenant(ctx, h.Authorizer, authz.ActionWriteProducts)
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewDeleteProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	_, err = h.CommandBus.Dispatch(ctx, command.DeleteProduct{ID: id})
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewDeleteProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if isProductNotFound(err) {
+		return products.NewDeleteProductsNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "product not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return products.NewDeleteProductsNoContent()
+}
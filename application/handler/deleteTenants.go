@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/product"
+	"github.com/t-kuni/cqrs-example/ent/tenant"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+)
+
+// DeleteTenants テナントを削除するハンドラです
+type DeleteTenants struct {
+	DBConnector db.IConnector
+	Authorizer  authz.IAuthorizer
+	Logger      system.ILogger
+}
+
+func NewDeleteTenants(conn db.IConnector, authorizer authz.IAuthorizer, logger system.ILogger) (*DeleteTenants, error) {
+	return &DeleteTenants{
+		DBConnector: conn,
+		Authorizer:  authorizer,
+		Logger:      logger,
+	}, nil
+}
+
+func (h DeleteTenants) Main(params tenants.DeleteTenantsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	err = h.Authorizer.Authorize(ctx, id, authz.ActionDeleteTenant)
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return tenants.NewDeleteTenantsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	var notFound bool
+	var referenced bool
+	err = h.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		exists, err := existsTenant(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !exists {
+			notFound = true
+			return nil
+		}
+
+		referenced, err = existsProductsByTenant(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if referenced {
+			return nil
+		}
+
+		return deleteTenant(ctx, tx, id)
+	})
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return tenants.NewDeleteTenantsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	if notFound {
+		return tenants.NewDeleteTenantsNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "tenant not found"))
+	}
+	if referenced {
+		return tenants.NewDeleteTenantsConflict().WithPayload(newErrorPayload(types.CodeConflict, "tenant has products"))
+	}
+
+	return tenants.NewDeleteTenantsNoContent()
+}
+
+func existsTenant(ctx context.Context, client *ent.Client, id uuid.UUID) (bool, error) {
+	return client.Tenant.Query().Where(tenant.ID(id)).Exist(ctx)
+}
+
+// existsProductsByTenant はテナントを跨いで product の有無を確認します
+func existsProductsByTenant(ctx context.Context, client *ent.Client, tenantID uuid.UUID) (bool, error) {
+	return client.Product.Query().Where(product.TenantID(tenantID)).Exist(tenancy.WithoutTenantScope(ctx))
+}
+
+func deleteTenant(ctx context.Context, client *ent.Client, id uuid.UUID) error {
+	return client.Tenant.DeleteOneID(id).Exec(ctx)
+}
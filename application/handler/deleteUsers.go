@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/tenant"
+	"github.com/t-kuni/cqrs-example/ent/user"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+)
+
+// DeleteUsers ユーザを削除するハンドラです
+type DeleteUsers struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewDeleteUsers(conn db.IConnector, logger system.ILogger) (*DeleteUsers, error) {
+	return &DeleteUsers{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h DeleteUsers) Main(params users.DeleteUsersParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	var notFound bool
+	var referenced bool
+	err = h.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		exists, err := existsUser(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !exists {
+			notFound = true
+			return nil
+		}
+
+		referenced, err = existsTenantsByOwner(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if referenced {
+			return nil
+		}
+
+		return deleteUser(ctx, tx, id)
+	})
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	if notFound {
+		return users.NewDeleteUsersNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "user not found"))
+	}
+	if referenced {
+		return users.NewDeleteUsersConflict().WithPayload(newErrorPayload(types.CodeConflict, "user owns tenants"))
+	}
+
+	return users.NewDeleteUsersNoContent()
+}
+
+func existsUser(ctx context.Context, client *ent.Client, id uuid.UUID) (bool, error) {
+	return client.User.Query().Where(user.ID(id)).Exist(ctx)
+}
+
+func existsTenantsByOwner(ctx context.Context, client *ent.Client, ownerID uuid.UUID) (bool, error) {
+	return client.Tenant.Query().Where(tenant.OwnerID(ownerID)).Exist(ctx)
+}
+
+func deleteUser(ctx context.Context, client *ent.Client, id uuid.UUID) error {
+	return client.User.DeleteOneID(id).Exec(ctx)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/category"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/categories"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+// GetCategories カテゴリを一覧で取得するハンドラです
+type GetCategories struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetCategories(conn db.IConnector, logger system.ILogger) (*GetCategories, error) {
+	return &GetCategories{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetCategories) Main(params categories.GetCategoriesParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	client := h.DBConnector.GetReplicaEnt()
+	page := resolvePage(params.Page)
+
+	total, err := countCategories(ctx, client)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	rows, err := listCategories(ctx, client, page)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	items := make([]*models.Category, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toCategoryResponse(row))
+	}
+
+	return categories.NewGetCategoriesOK().WithPayload(&categories.GetCategoriesOKBody{
+		Categories: items,
+		Page:       util.Ptr(page),
+		MaxPage:    util.Ptr(calcMaxPage(total)),
+	})
+}
+
+func countCategories(ctx context.Context, client *ent.Client) (int, error) {
+	return client.Category.Query().Count(ctx)
+}
+
+func listCategories(ctx context.Context, client *ent.Client, page int64) ([]*ent.Category, error) {
+	return client.Category.Query().
+		Order(ent.Asc(category.FieldName), ent.Asc(category.FieldID)).
+		Offset(pageOffset(page)).
+		Limit(perPage).
+		All(ctx)
+}
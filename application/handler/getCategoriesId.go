@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/categories"
+)
+
+// GetCategoriesID カテゴリを取得するハンドラです
+type GetCategoriesID struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetCategoriesID(conn db.IConnector, logger system.ILogger) (*GetCategoriesID, error) {
+	return &GetCategoriesID{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetCategoriesID) Main(params categories.GetCategoriesIDParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	row, err := findCategory(ctx, h.DBConnector.GetReplicaEnt(), id)
+	if ent.IsNotFound(err) {
+		return categories.NewGetCategoriesIDNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "category not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return categories.NewGetCategoriesIDOK().WithPayload(toCategoryResponse(row))
+}
+
+func findCategory(ctx context.Context, client *ent.Client, id uuid.UUID) (*ent.Category, error) {
+	return client.Category.Get(ctx, id)
+}
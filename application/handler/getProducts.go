@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+// GetProducts productを検索するハンドラです
+type GetProducts struct {
+	ProductSearchService service.IProductSearchService
+	Logger               system.ILogger
+}
+
+func NewGetProducts(productSearchService service.IProductSearchService, logger system.ILogger) (*GetProducts, error) {
+	return &GetProducts{
+		ProductSearchService: productSearchService,
+		Logger:               logger,
+	}, nil
+}
+
+func (h GetProducts) Main(params products.GetProductsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	page := resolvePage(params.Page)
+
+	keyword := ""
+	if params.Q != nil {
+		keyword = *params.Q
+	}
+
+	result, err := h.ProductSearchService.Search(ctx, keyword, pageOffset(page), perPage)
+	if businessErr, ok := asBusinessError(err); ok {
+		return products.NewGetProductsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, businessErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	items := make([]*models.ProductSearchItem, 0, len(result.Products))
+	for _, doc := range result.Products {
+		items = append(items, toProductSearchItemResponse(doc))
+	}
+
+	return products.NewGetProductsOK().WithPayload(&products.GetProductsOKBody{
+		Products: items,
+		Page:     util.Ptr(page),
+		MaxPage:  util.Ptr(calcMaxPage(int(result.Total))),
+	})
+}
+
+// toProductSearchItemResponse はOpenSearchのドキュメントをレスポンス用のモデルに変換します
+func toProductSearchItemResponse(doc service.ProductDocument) *models.ProductSearchItem {
+	return &models.ProductSearchItem{
+		ID:           util.Ptr(strfmt.UUID(doc.ID)),
+		TenantID:     util.Ptr(strfmt.UUID(doc.Tenant.ID)),
+		CategoryID:   util.Ptr(strfmt.UUID(doc.Category.ID)),
+		CategoryName: util.Ptr(doc.Category.Name),
+		Name:         util.Ptr(doc.Name),
+		Price:        util.Ptr(doc.Price),
+		Properties: &models.ProductProperties{
+			Size:      doc.Properties.Size,
+			Latitude:  doc.Properties.Latitude,
+			Longitude: doc.Properties.Longitude,
+			Color:     doc.Properties.Color,
+		},
+		ListedAt: util.Ptr(strfmt.DateTime(doc.ListedAt)),
+	}
+}
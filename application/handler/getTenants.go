@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/tenant"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+// GetTenants テナントを一覧で取得するハンドラです
+type GetTenants struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetTenants(conn db.IConnector, logger system.ILogger) (*GetTenants, error) {
+	return &GetTenants{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetTenants) Main(params tenants.GetTenantsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	client := h.DBConnector.GetReplicaEnt()
+	page := resolvePage(params.Page)
+
+	total, err := countTenants(ctx, client)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	rows, err := listTenants(ctx, client, page)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	items := make([]*models.Tenant, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toTenantResponse(row))
+	}
+
+	return tenants.NewGetTenantsOK().WithPayload(&tenants.GetTenantsOKBody{
+		Tenants: items,
+		Page:    util.Ptr(page),
+		MaxPage: util.Ptr(calcMaxPage(total)),
+	})
+}
+
+func countTenants(ctx context.Context, client *ent.Client) (int, error) {
+	return client.Tenant.Query().Count(ctx)
+}
+
+func listTenants(ctx context.Context, client *ent.Client, page int64) ([]*ent.Tenant, error) {
+	return client.Tenant.Query().
+		Order(ent.Asc(tenant.FieldName), ent.Asc(tenant.FieldID)).
+		Offset(pageOffset(page)).
+		Limit(perPage).
+		All(ctx)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+)
+
+// GetTenantsID テナントを取得するハンドラです
+type GetTenantsID struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetTenantsID(conn db.IConnector, logger system.ILogger) (*GetTenantsID, error) {
+	return &GetTenantsID{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetTenantsID) Main(params tenants.GetTenantsIDParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	row, err := findTenant(ctx, h.DBConnector.GetReplicaEnt(), id)
+	if ent.IsNotFound(err) {
+		return tenants.NewGetTenantsIDNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "tenant not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return tenants.NewGetTenantsIDOK().WithPayload(toTenantResponse(row))
+}
+
+func findTenant(ctx context.Context, client *ent.Client, id uuid.UUID) (*ent.Tenant, error) {
+	return client.Tenant.Get(ctx, id)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/user"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+// GetUsers ユーザを一覧で取得するハンドラです
+type GetUsers struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetUsers(conn db.IConnector, logger system.ILogger) (*GetUsers, error) {
+	return &GetUsers{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetUsers) Main(params users.GetUsersParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	client := h.DBConnector.GetReplicaEnt()
+	page := resolvePage(params.Page)
+
+	total, err := countUsers(ctx, client)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	rows, err := listUsers(ctx, client, page)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	items := make([]*models.User, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toUserResponse(row))
+	}
+
+	return users.NewGetUsersOK().WithPayload(&users.GetUsersOKBody{
+		Users:   items,
+		Page:    util.Ptr(page),
+		MaxPage: util.Ptr(calcMaxPage(total)),
+	})
+}
+
+func countUsers(ctx context.Context, client *ent.Client) (int, error) {
+	return client.User.Query().Count(ctx)
+}
+
+func listUsers(ctx context.Context, client *ent.Client, page int64) ([]*ent.User, error) {
+	return client.User.Query().
+		Order(ent.Asc(user.FieldName), ent.Asc(user.FieldID)).
+		Offset(pageOffset(page)).
+		Limit(perPage).
+		All(ctx)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+)
+
+// GetUsersID ユーザを取得するハンドラです
+type GetUsersID struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewGetUsersID(conn db.IConnector, logger system.ILogger) (*GetUsersID, error) {
+	return &GetUsersID{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h GetUsersID) Main(params users.GetUsersIDParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	row, err := findUser(ctx, h.DBConnector.GetReplicaEnt(), id)
+	if ent.IsNotFound(err) {
+		return users.NewGetUsersIDNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "user not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return users.NewGetUsersIDOK().WithPayload(toUserResponse(row))
+}
+
+func findUser(ctx context.Context, client *ent.Client, id uuid.UUID) (*ent.User, error) {
+	return client.User.Get(ctx, id)
+}
@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/application/handler"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+	"github.com/t-kuni/cqrs-example/testUtil"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+func TestGetUsers(t *testing.T) {
+	t.Run("ユーザを名前順で一覧取得できること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.PrepareTestData(func(db *ent.Client) {
+			ctx := context.Background()
+			db.User.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000002")).
+				SetName("ユーザ2").
+				SaveX(ctx)
+			db.User.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+				SetName("ユーザ1").
+				SaveX(ctx)
+		})
+
+		var testee *handler.GetUsers
+		cont.Exec(func(h *handler.GetUsers) {
+			testee = h
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/users?page=1", nil)
+		resp := testee.Main(users.GetUsersParams{
+			HTTPRequest: req,
+			Page:        util.Ptr(int64(1)),
+		})
+
+		w := httptest.NewRecorder()
+		resp.WriteResponse(w, runtime.JSONProducer())
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), body["page"])
+		assert.Equal(t, float64(1), body["maxPage"])
+		assert.Len(t, body["users"], 2)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000001", body["users"].([]interface{})[0].(map[string]interface{})["id"])
+		assert.Equal(t, "ユーザ1", body["users"].([]interface{})[0].(map[string]interface{})["name"])
+		assert.Equal(t, "ユーザ2", body["users"].([]interface{})[1].(map[string]interface{})["name"])
+	})
+}
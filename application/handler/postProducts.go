@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+)
+
+// PostProducts productを登録するハンドラです
+type PostProducts struct {
+	CommandBus command.IBus
+	Authorizer authz.IAuthorizer
+	Logger     system.ILogger
+}
+
+func NewPostProducts(commandBus command.IBus, authorizer authz.IAuthorizer, logger system.ILogger) (*PostProducts, error) {
+	return &PostProducts{
+		CommandBus: commandBus,
+		Authorizer: authorizer,
+		Logger:     logger,
+	}, nil
+}
+
+func (h PostProducts) Main(params products.PostProductsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	tenantID, err := uuid.Parse(params.Body.TenantID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	categoryID, err := uuid.Parse(params.Body.CategoryID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	err = h.Authorizer.Authorize(ctx, tenantID, authz.ActionWriteProducts)
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewPostProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	created, err := command.Dispatch[*ent.Product](ctx, h.CommandBus, command.CreateProduct{
+		TenantID:   tenantID,
+		CategoryID: categoryID,
+		Name:       *params.Body.Name,
+		Price:      *params.Body.Price,
+		Properties: toProductPropertiesModel(params.Body.Properties),
+	})
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewPostProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if businessErr, ok := asBusinessError(err); ok {
+		return products.NewPostProductsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, businessErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return products.NewPostProductsOK().WithPayload(toProductResponse(created))
+}
@@ -0,0 +1,150 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/application/handler"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+	"github.com/t-kuni/cqrs-example/testUtil"
+	"github.com/t-kuni/cqrs-example/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPostProducts(t *testing.T) {
+	t.Run("productを登録しOpenSearchに同期できること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		uuidGenerator := system.NewMockIUuidGenerator(cont.MockCtrl)
+		uuidGenerator.EXPECT().Generate().Return("b3c9e1a4-0000-4000-8000-000000000001", nil)
+		testUtil.Override[system.IUuidGenerator](cont, uuidGenerator)
+
+		openSearchApi := api.NewMockIOpenSearchApi(cont.MockCtrl)
+		openSearchApi.EXPECT().
+			IndexDocument(gomock.Any(), "products", "b3c9e1a4-0000-4000-8000-000000000001", gomock.Any()).
+			Return(nil)
+		testUtil.Override[api.IOpenSearchApi](cont, openSearchApi)
+
+		cont.PrepareTestData(func(db *ent.Client) {
+			ctx := context.Background()
+			db.User.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+				SetName("ユーザ1").
+				SaveX(ctx)
+			db.Tenant.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000011")).
+				SetOwnerID(uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+				SetName("テナント1").
+				SaveX(ctx)
+			db.Category.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000021")).
+				SetName("カテゴリ1").
+				SaveX(ctx)
+		})
+
+		var testee *handler.PostProducts
+		var conn db.IConnector
+		cont.Exec(func(h *handler.PostProducts, c db.IConnector) {
+			testee = h
+			conn = c
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/products", nil)
+		resp := testee.Main(products.PostProductsParams{
+			HTTPRequest: req,
+			Body: &models.PostProductsRequest{
+				TenantID:   util.Ptr(strfmt.UUID("00000000-0000-0000-0000-000000000011")),
+				CategoryID: util.Ptr(strfmt.UUID("00000000-0000-0000-0000-000000000021")),
+				Name:       util.Ptr("商品1"),
+				Price:      util.Ptr(int64(1000)),
+				Properties: &models.ProductProperties{
+					Size:  util.Ptr("M"),
+					Color: util.Ptr("red"),
+				},
+			},
+		})
+
+		w := httptest.NewRecorder()
+		resp.WriteResponse(w, runtime.JSONProducer())
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		p, err := conn.GetEnt().Product.Get(context.Background(), uuid.MustParse("b3c9e1a4-0000-4000-8000-000000000001"))
+		assert.NoError(t, err)
+		assert.Equal(t, "商品1", p.Name)
+		assert.Equal(t, int64(1000), p.Price)
+		assert.Equal(t, "M", *p.Properties.Size)
+		assert.Equal(t, "red", *p.Properties.Color)
+		assert.Nil(t, p.Properties.Latitude)
+		assert.Equal(t, testUtil.MustNewDateTime("2025-01-02T03:04:05Z"), p.ListedAt.UTC())
+	})
+	t.Run("テナントのownerでもmemberでもないユーザの場合は403を返すこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.PrepareTestData(func(db *ent.Client) {
+			ctx := context.Background()
+			db.User.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+				SetName("ユーザ1").
+				SaveX(ctx)
+			db.User.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000002")).
+				SetName("ユーザ2").
+				SaveX(ctx)
+			db.Tenant.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000011")).
+				SetOwnerID(uuid.MustParse("00000000-0000-0000-0000-000000000001")).
+				SetName("テナント1").
+				SaveX(ctx)
+			db.Category.Create().
+				SetID(uuid.MustParse("00000000-0000-0000-0000-000000000021")).
+				SetName("カテゴリ1").
+				SaveX(ctx)
+		})
+
+		var testee *handler.PostProducts
+		var conn db.IConnector
+		cont.Exec(func(h *handler.PostProducts, c db.IConnector) {
+			testee = h
+			conn = c
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/products", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{
+			UserID:    uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			TenantIDs: []uuid.UUID{uuid.MustParse("00000000-0000-0000-0000-000000000011")},
+		}))
+		resp := testee.Main(products.PostProductsParams{
+			HTTPRequest: req,
+			Body: &models.PostProductsRequest{
+				TenantID:   util.Ptr(strfmt.UUID("00000000-0000-0000-0000-000000000011")),
+				CategoryID: util.Ptr(strfmt.UUID("00000000-0000-0000-0000-000000000021")),
+				Name:       util.Ptr("商品1"),
+				Price:      util.Ptr(int64(1000)),
+			},
+		})
+
+		w := httptest.NewRecorder()
+		resp.WriteResponse(w, runtime.JSONProducer())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		count, err := conn.GetEnt().Product.Query().Count(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+)
+
+// PostTenants テナントを登録するハンドラです
+type PostTenants struct {
+	DBConnector   db.IConnector
+	UuidGenerator system.IUuidGenerator
+	Logger        system.ILogger
+}
+
+func NewPostTenants(conn db.IConnector, uuidGenerator system.IUuidGenerator, logger system.ILogger) (*PostTenants, error) {
+	return &PostTenants{
+		DBConnector:   conn,
+		UuidGenerator: uuidGenerator,
+		Logger:        logger,
+	}, nil
+}
+
+func (h PostTenants) Main(params tenants.PostTenantsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	ownerID, err := uuid.Parse(params.Body.OwnerID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	idStr, err := h.UuidGenerator.Generate()
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	var created *ent.Tenant
+	var validationMessage string
+	err = h.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		ownerExists, err := existsUser(ctx, tx, ownerID)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !ownerExists {
+			validationMessage = "owner not found"
+			return nil
+		}
+
+		created, err = createTenant(ctx, tx, id, ownerID, *params.Body.Name)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		return nil
+	})
+	// 他のユーザを owner とするテナントの登録は ent の privacy ルールで拒否される
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return tenants.NewPostTenantsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	if validationMessage != "" {
+		return tenants.NewPostTenantsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, validationMessage))
+	}
+
+	return tenants.NewPostTenantsOK().WithPayload(toTenantResponse(created))
+}
+
+func createTenant(ctx context.Context, client *ent.Client, id uuid.UUID, ownerID uuid.UUID, name string) (*ent.Tenant, error) {
+	return client.Tenant.Create().
+		SetID(id).
+		SetOwnerID(ownerID).
+		SetName(name).
+		Save(ctx)
+}
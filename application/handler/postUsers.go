@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+)
+
+// PostUsers ユーザを登録するハンドラです
+type PostUsers struct {
+	DBConnector   db.IConnector
+	UuidGenerator system.IUuidGenerator
+	Logger        system.ILogger
+}
+
+func NewPostUsers(conn db.IConnector, uuidGenerator system.IUuidGenerator, logger system.ILogger) (*PostUsers, error) {
+	return &PostUsers{
+		DBConnector:   conn,
+		UuidGenerator: uuidGenerator,
+		Logger:        logger,
+	}, nil
+}
+
+func (h PostUsers) Main(params users.PostUsersParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	idStr, err := h.UuidGenerator.Generate()
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	created, err := createUser(ctx, h.DBConnector.GetEnt(), id, *params.Body.Name)
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return users.NewPostUsersOK().WithPayload(toUserResponse(created))
+}
+
+func createUser(ctx context.Context, client *ent.Client, id uuid.UUID, name string) (*ent.User, error) {
+	return client.User.Create().
+		SetID(id).
+		SetName(name).
+		Save(ctx)
+}
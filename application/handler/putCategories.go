@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/categories"
+)
+
+// PutCategories カテゴリを更新するハンドラです
+type PutCategories struct {
+	CommandBus command.IBus
+	Logger     system.ILogger
+}
+
+func NewPutCategories(commandBus command.IBus, logger system.ILogger) (*PutCategories, error) {
+	return &PutCategories{
+		CommandBus: commandBus,
+		Logger:     logger,
+	}, nil
+}
+
+func (h PutCategories) Main(params categories.PutCategoriesParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	updated, err := command.Dispatch[*ent.Category](ctx, h.CommandBus, command.RenameCategory{
+		ID:   id,
+		Name: *params.Body.Name,
+	})
+	if ent.IsNotFound(err) {
+		return categories.NewPutCategoriesNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "category not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return categories.NewPutCategoriesOK().WithPayload(toCategoryResponse(updated))
+}
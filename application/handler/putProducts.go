@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+)
+
+// PutProducts productを更新するハンドラです
+type PutProducts struct {
+	CommandBus command.IBus
+	Authorizer authz.IAuthorizer
+	Logger     system.ILogger
+}
+
+func NewPutProducts(commandBus command.IBus, authorizer authz.IAuthorizer, logger system.ILogger) (*PutProducts, error) {
+	return &PutProducts{
+		CommandBus: commandBus,
+		Authorizer: authorizer,
+		Logger:     logger,
+	}, nil
+}
+
+func (h PutProducts) Main(params products.PutProductsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	categoryID, err := uuid.Parse(params.Body.CategoryID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	err = authorizeRequestTenant(ctx, h.Authorizer, authz.ActionWriteProducts)
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewPutProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if businessErr, ok := asBusinessError(err); ok {
+		return products.NewPutProductsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, businessErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	updated, err := command.Dispatch[*ent.Product](ctx, h.CommandBus, command.UpdateProduct{
+		ID:         id,
+		Version:    *params.Body.Version,
+		CategoryID: categoryID,
+		Name:       *params.Body.Name,
+		Price:      *params.Body.Price,
+		Properties: toProductPropertiesModel(params.Body.Properties),
+	})
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return products.NewPutProductsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if isProductNotFound(err) {
+		return products.NewPutProductsNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "product not found"))
+	}
+	if businessErr, ok := asBusinessError(err); ok {
+		return products.NewPutProductsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, businessErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return products.NewPutProductsOK().WithPayload(toProductResponse(updated))
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/application/handler"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+	"github.com/t-kuni/cqrs-example/util"
+	"go.uber.org/mock/gomock"
+)
+
+func newPutProductsParams(req *http.Request) products.PutProductsParams {
+	return products.PutProductsParams{
+		HTTPRequest: req,
+		ID:          strfmt.UUID("00000000-0000-0000-0000-000000000001"),
+		Body: &models.PutProductsRequest{
+			Version:    util.Ptr(int64(3)),
+			CategoryID: util.Ptr(strfmt.UUID("00000000-0000-0000-0000-000000000021")),
+			Name:       util.Ptr("商品1"),
+			Price:      util.Ptr(int64(1000)),
+		},
+	}
+}
+
+func TestPutProducts(t *testing.T) {
+	t.Run("処理対象のテナントを解決できていない場合は 400 を返し、コマンドを実行しないこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		testee, err := handler.NewPutProducts(command.NewMockIBus(ctrl), authz.NewMockIAuthorizer(ctrl), nil)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/products/00000000-0000-0000-0000-000000000001", nil)
+		req = req.WithContext(tenancy.WithUnresolvedTenant(req.Context()))
+		resp := testee.Main(newPutProductsParams(req))
+
+		badRequest, ok := resp.(*products.PutProductsBadRequest)
+		if assert.True(t, ok) {
+			assert.Equal(t, string(types.CodeBusinessRule), badRequest.Payload.ErrorCode)
+		}
+	})
+
+	t.Run("テナントの範囲に制限されていない場合は権限を確認せずにコマンドを実行すること", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		bus := command.NewMockIBus(ctrl)
+		bus.EXPECT().Dispatch(gomock.Any(), gomock.Any()).
+			Return(nil, types.NewConflictError("product was modified by another request", 4))
+		testee, err := handler.NewPutProducts(bus, authz.NewMockIAuthorizer(ctrl), nil)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/products/00000000-0000-0000-0000-000000000001", nil)
+		req = req.WithContext(tenancy.WithoutTenantScope(req.Context()))
+		resp := testee.Main(newPutProductsParams(req))
+
+		w := httptest.NewRecorder()
+		resp.WriteResponse(w, runtime.JSONProducer())
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("ハンドラのエラーも Accept ヘッダで要求された場合は problem details で返すこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		bus := command.NewMockIBus(ctrl)
+		bus.EXPECT().Dispatch(gomock.Any(), gomock.Any()).
+			Return(nil, types.NewConflictError("product was modified by another request", 4))
+		testee, err := handler.NewPutProducts(bus, authz.NewMockIAuthorizer(ctrl), nil)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/products/00000000-0000-0000-0000-000000000001", nil)
+		req.Header.Set("Accept", errors.ProblemJSONMediaType)
+		resp := testee.Main(newPutProductsParams(req))
+
+		w := httptest.NewRecorder()
+		resp.WriteResponse(w, runtime.JSONProducer())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, errors.ProblemJSONMediaType, w.Header().Get("Content-Type"))
+
+		var problem errors.ProblemDetails
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, string(types.CodeConflict), problem.ErrorCode)
+		assert.Equal(t, int64(4), problem.CurrentVersion)
+	})
+}
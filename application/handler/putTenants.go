@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+)
+
+// PutTenants テナントを更新するハンドラです
+type PutTenants struct {
+	DBConnector db.IConnector
+	Authorizer  authz.IAuthorizer
+	Logger      system.ILogger
+}
+
+func NewPutTenants(conn db.IConnector, authorizer authz.IAuthorizer, logger system.ILogger) (*PutTenants, error) {
+	return &PutTenants{
+		DBConnector: conn,
+		Authorizer:  authorizer,
+		Logger:      logger,
+	}, nil
+}
+
+func (h PutTenants) Main(params tenants.PutTenantsParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	ownerID, err := uuid.Parse(params.Body.OwnerID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	err = h.Authorizer.Authorize(ctx, id, authz.ActionUpdateTenant)
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return tenants.NewPutTenantsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	var updated *ent.Tenant
+	var notFound bool
+	var validationMessage string
+	err = h.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		tenantExists, err := existsTenant(ctx, tx, id)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !tenantExists {
+			notFound = true
+			return nil
+		}
+
+		ownerExists, err := existsUser(ctx, tx, ownerID)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !ownerExists {
+			validationMessage = "owner not found"
+			return nil
+		}
+
+		updated, err = updateTenant(ctx, tx, id, *params.Body.Version, ownerID, *params.Body.Name)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		return nil
+	})
+	if forbiddenErr, ok := asForbiddenError(err); ok {
+		return tenants.NewPutTenantsForbidden().WithPayload(newErrorPayload(types.CodeForbidden, forbiddenErr.Message))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+	if notFound {
+		return tenants.NewPutTenantsNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "tenant not found"))
+	}
+	if validationMessage != "" {
+		return tenants.NewPutTenantsBadRequest().WithPayload(newErrorPayload(types.CodeBusinessRule, validationMessage))
+	}
+
+	return tenants.NewPutTenantsOK().WithPayload(toTenantResponse(updated))
+}
+
+// updateTenant はテナントを更新します
+// version が取得時から変わっている場合は types.ConflictError を返します
+func updateTenant(ctx context.Context, client *ent.Client, id uuid.UUID, version int64, ownerID uuid.UUID, name string) (*ent.Tenant, error) {
+	return client.Tenant.UpdateOneID(id).
+		SetVersion(version).
+		SetOwnerID(ownerID).
+		SetName(name).
+		Save(ctx)
+}
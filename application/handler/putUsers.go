@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
+)
+
+// PutUsers ユーザを更新するハンドラです
+type PutUsers struct {
+	DBConnector db.IConnector
+	Logger      system.ILogger
+}
+
+func NewPutUsers(conn db.IConnector, logger system.ILogger) (*PutUsers, error) {
+	return &PutUsers{
+		DBConnector: conn,
+		Logger:      logger,
+	}, nil
+}
+
+func (h PutUsers) Main(params users.PutUsersParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+
+	id, err := uuid.Parse(params.ID.String())
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	updated, err := updateUser(ctx, h.DBConnector.GetEnt(), id, *params.Body.Name)
+	if ent.IsNotFound(err) {
+		return users.NewPutUsersNotFound().WithPayload(newErrorPayload(types.CodeNotFound, "user not found"))
+	}
+	if err != nil {
+		return errors.NewErrorResponder(h.Logger, params.HTTPRequest, eris.Wrap(err, ""))
+	}
+
+	return users.NewPutUsersOK().WithPayload(toUserResponse(updated))
+}
+
+func updateUser(ctx context.Context, client *ent.Client, id uuid.UUID, name string) (*ent.User, error) {
+	return client.User.UpdateOneID(id).
+		SetName(name).
+		Save(ctx)
+}
@@ -1,72 +1,339 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/joho/godotenv"
-	"github.com/t-kuni/cqrs-example/di"
-	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
-	"go.uber.org/fx"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	atlasMigrate "ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqltool"
+	"entgo.io/ent/dialect/sql/schema"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/di"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent/migrate"
+	dbImpl "github.com/t-kuni/cqrs-example/infrastructure/db"
+	"go.uber.org/fx"
 )
 
+const usage = `Usage: go run commands/migrate/main.go [command] [flags]
+
+Commands:
+  up            apply pending migrations (default)
+  down          roll back applied migrations (default: the latest one)
+  status        show the applied and pending migrations
+  diff <name>   generate a migration from the difference between ent/schema and the migration files
+
+Flags:
+`
+
 func main() {
 	_, file, _, ok := runtime.Caller(0)
 	if !ok {
 		panic("Could not get current file path")
 	}
 	directory := filepath.Dir(file)
-	godotenv.Load(filepath.Join(directory, "..", "..", ".env"))
+	migrationDir := filepath.Join(directory, "..", "..", "infrastructure", "db", "migrations")
 
 	var (
-		reset = flag.Bool("reset", false, "reset database before migration")
+		reset    = flag.Bool("reset", false, "reset database before migration (up only)")
+		forceEnv = flag.String("force-env", "", "allow --reset in APP_ENV=production by passing the same value as APP_ENV")
+		yes      = flag.Bool("yes", false, "reset without the confirmation prompt")
+		backup   = flag.String("backup", "", "write a logical backup (SQL) of the database to this file before --reset")
+		dryRun   = flag.Bool("dry-run", false, "print the SQL without executing it (up and down only)")
+		steps    = flag.Int("steps", 0, "number of migrations to apply or roll back (up: all by default, down: 1 by default)")
+		devURL   = flag.String("dev-url", "", "URL of an empty database used to calculate the diff (default: mysql://<DB_USER>:<DB_PASSWORD>@<DB_HOST>:<DB_PORT>/<DB_DATABASE>_dev)")
 	)
-	flag.Parse()
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	args, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
 
-	database := os.Getenv("DB_DATABASE")
-	fmt.Println("Target Database: " + database)
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "down" && !isFlagSet("steps") {
+		*steps = 1
+	}
+	if *reset && (command != "up" || *dryRun) {
+		panic("--reset can only be used with up and without --dry-run")
+	}
 
 	ctx := context.Background()
-	app := di.NewApp(fx.Invoke(func(conn db.IConnector) {
-		if *reset {
-			fmt.Println("Resetting database...")
-
-			// データベースを削除して再作成するためにはinformation_schemaに接続する必要がある
-			db := conn.GetDB()
+	opts := []fx.Option{
+		// カレントディレクトリに関わらずリポジトリ直下の .env を読み込む
+		fx.Decorate(func() (*config.Config, error) {
+			return config.Load(filepath.Join(directory, "..", "..", ".env"))
+		}),
+	}
+	switch command {
+	case "up":
+		opts = append(opts, fx.Invoke(func(conn db.IConnector, migrator db.IMigrator, cfg *config.Config) error {
+			fmt.Println("Target Database: " + cfg.DB.Database)
+			if *reset {
+				if err := checkResetAllowed(cfg.App.Env, *forceEnv); err != nil {
+					return err
+				}
+				if !*yes {
+					if err := confirmReset(cfg.App.Env, cfg.DB.Database); err != nil {
+						return err
+					}
+				}
+				if *backup != "" {
+					if err := backupDatabase(ctx, conn, *backup); err != nil {
+						return err
+					}
+				}
+				if err := resetDatabase(conn, cfg.DB.Database); err != nil {
+					return err
+				}
+			}
 
-			// データベースを削除
-			_, err := db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", database))
+			migrations, err := migrator.Up(ctx, *steps, *dryRun)
+			printMigrations(migrations, "Applied", *dryRun, func(m db.Migration) []string { return m.Up }, "up")
 			if err != nil {
-				panic(fmt.Errorf("failed to drop database: %w", err))
+				return err
+			}
+			if len(migrations) == 0 {
+				fmt.Println("No pending migrations")
 			}
+			return nil
+		}))
+	case "down":
+		opts = append(opts, fx.Invoke(func(migrator db.IMigrator, cfg *config.Config) error {
+			fmt.Println("Target Database: " + cfg.DB.Database)
 
-			// データベースを作成
-			_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s`", database))
+			migrations, err := migrator.Down(ctx, *steps, *dryRun)
+			printMigrations(migrations, "Rolled back", *dryRun, func(m db.Migration) []string { return m.Down }, "down")
 			if err != nil {
-				panic(fmt.Errorf("failed to create database: %w", err))
+				return err
 			}
+			if len(migrations) == 0 {
+				fmt.Println("No applied migrations")
+			}
+			return nil
+		}))
+	case "status":
+		opts = append(opts, fx.Invoke(func(migrator db.IMigrator, cfg *config.Config) error {
+			fmt.Println("Target Database: " + cfg.DB.Database)
 
-			_, err = db.Exec(fmt.Sprintf("USE `%s`", database))
+			statuses, err := migrator.Status(ctx)
 			if err != nil {
-				panic(fmt.Errorf("failed to use database: %w", err))
+				return err
 			}
-
-			fmt.Println("Database reset successfully!")
+			printStatuses(statuses)
+			return nil
+		}))
+	case "diff":
+		if len(args) != 1 {
+			flag.Usage()
+			os.Exit(2)
 		}
+		name := args[0]
+		opts = append(opts, fx.Invoke(func(cfg *config.Config) error {
+			url := *devURL
+			if url == "" {
+				url = defaultDevURL(cfg.DB)
+			}
+			return diff(ctx, migrationDir, url, name)
+		}))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 
-		if err := conn.Migrate(ctx); err != nil {
-			panic(err)
-		}
-	}))
+	app := di.NewApp(opts...)
 	defer app.Stop(ctx)
 
-	err := app.Start(ctx)
+	err = app.Start(ctx)
 	if err != nil {
 		panic(err)
 	}
+}
+
+// parseArgs は サブコマンドや名前の前後のどちらに指定したフラグも解析し、フラグ以外の引数を返します
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// checkResetAllowed は 本番環境のデータベースを誤ってリセットしないよう、APP_ENV が production の場合は
+// --force-env に APP_ENV と同じ値が指定されている場合のみリセットを許可します
+func checkResetAllowed(env string, forceEnv string) error {
+	if env != config.EnvProduction || forceEnv == env {
+		return nil
+	}
+	return fmt.Errorf("refusing to reset the database in APP_ENV=%s (pass --force-env=%s to reset it anyway)", env, env)
+}
+
+// confirmReset は リセットするデータベースの名前を入力させ、一致しない場合はエラーを返します
+func confirmReset(env string, database string) error {
+	fmt.Printf("This will DROP and recreate the database `%s` (APP_ENV=%s).\n", database, env)
+	fmt.Print("Type the database name to continue: ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("reset aborted: could not read the confirmation: %w", err)
+	}
+	if strings.TrimSpace(answer) != database {
+		return fmt.Errorf("reset aborted: the input did not match the database name %q", database)
+	}
+	return nil
+}
+
+// backupDatabase は リセットする前のデータベースの論理バックアップを path に書き出します
+func backupDatabase(ctx context.Context, conn db.IConnector, path string) error {
+	fmt.Println("Backing up database to " + path + "...")
 
-	fmt.Println("Migrate successfully!")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create the backup file: %w", err)
+	}
+	if err := dbImpl.DumpDatabase(ctx, conn.GetDB(), f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to back up the database (the database was not reset): %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write the backup file: %w", err)
+	}
+
+	fmt.Println("Database backed up successfully!")
+	return nil
+}
+
+// resetDatabase は データベースを削除して再作成します
+func resetDatabase(conn db.IConnector, database string) error {
+	fmt.Println("Resetting database...")
+
+	// データベースを削除して再作成するためにはinformation_schemaに接続する必要がある
+	db := conn.GetDB()
+
+	// データベースを削除
+	_, err := db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", database))
+	if err != nil {
+		return fmt.Errorf("failed to drop database: %w", err)
+	}
+
+	// データベースを作成
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s`", database))
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	_, err = db.Exec(fmt.Sprintf("USE `%s`", database))
+	if err != nil {
+		return fmt.Errorf("failed to use database: %w", err)
+	}
+
+	fmt.Println("Database reset successfully!")
+	return nil
+}
+
+// printMigrations は 実行したマイグレーションを表示します。dryRun の場合は実行するSQLを表示します
+func printMigrations(migrations []db.Migration, verb string, dryRun bool, statements func(db.Migration) []string, direction string) {
+	for _, m := range migrations {
+		if !dryRun {
+			fmt.Printf("%s: %s_%s\n", verb, m.Version, m.Name)
+			continue
+		}
+		fmt.Printf("-- %s_%s.%s.sql\n", m.Version, m.Name, direction)
+		for _, statement := range statements(m) {
+			fmt.Println(statement + ";")
+		}
+	}
+}
+
+func printStatuses(statuses []db.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	ahead := false
+	for _, s := range statuses {
+		status := "pending"
+		switch {
+		case s.Unknown:
+			status = "unknown (not in the code)"
+			ahead = true
+		case s.AppliedAt != nil && s.Modified:
+			status = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05") + " (modified after applied)"
+		case s.AppliedAt != nil:
+			status = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, status)
+	}
+	w.Flush()
+
+	if ahead {
+		fmt.Println("The database is ahead of the code. up and down are refused until the code is updated.")
+	}
+}
+
+// diff は migrationDir のマイグレーションを開発用のDBで再生した状態と ent/schema の差分から、新しいマイグレーションファイルを生成します
+func diff(ctx context.Context, migrationDir string, devURL string, name string) error {
+	dir, err := sqltool.NewGolangMigrateDir(migrationDir)
+	if err != nil {
+		return err
+	}
+
+	err = migrate.NamedDiff(ctx, devURL, name,
+		schema.WithDir(dir),
+		schema.WithMigrationMode(schema.ModeReplay),
+		schema.WithFormatter(sqltool.GolangMigrateFormatter),
+		schema.WithErrNoPlan(true),
+	)
+	if errors.Is(err, atlasMigrate.ErrNoPlan) {
+		fmt.Println("No schema changes")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Generated migration files in " + migrationDir)
+	return nil
+}
+
+// defaultDevURL は 差分の計算に使用する開発用のDB（<DB_DATABASE>_dev）のURLを返します
+func defaultDevURL(cfg config.DB) string {
+	u := url.URL{
+		Scheme: "mysql",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:   "/" + cfg.Database + "_dev",
+	}
+	return u.String()
 }
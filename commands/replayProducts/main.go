@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/t-kuni/cqrs-example/di"
+	"github.com/t-kuni/cqrs-example/domain/eventsourcing"
+	"go.uber.org/fx"
+)
+
+func main() {
+	ctx := context.Background()
+	app := di.NewApp(fx.Invoke(func(projector eventsourcing.IProductProjector) {
+		fmt.Println("Starting product replay from event store...")
+
+		replayed, err := projector.Replay(ctx)
+		if err != nil {
+			panic(fmt.Errorf("failed to replay products: %w", err))
+		}
+		fmt.Printf("Replayed products: %d\n", replayed)
+
+		fmt.Println("Product replay completed successfully!")
+	}))
+
+	defer app.Stop(ctx)
+	err := app.Start(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
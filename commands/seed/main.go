@@ -6,7 +6,6 @@ import (
 	"flag"
 	"fmt"
 	"github.com/ghodss/yaml"
-	"github.com/joho/godotenv"
 	"github.com/romanyx/polluter"
 	"github.com/t-kuni/cqrs-example/di"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
@@ -16,8 +15,6 @@ import (
 )
 
 func main() {
-	godotenv.Load(filepath.Join(".env"))
-
 	var (
 		seed = flag.String("seed", "basic", "seed name")
 	)
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/t-kuni/cqrs-example/di"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"go.uber.org/fx"
+)
+
+// subscriberParams は DIコンテナに登録された永続購読者の一覧を受け取るための構造体です
+type subscriberParams struct {
+	fx.In
+
+	Lifecycle   fx.Lifecycle
+	Runner      event.IDurableRunner
+	Subscribers []event.ISubscriber `group:"durableEventSubscribers"`
+}
+
+func main() {
+	var (
+		subscriberName = flag.String("subscriber", "", "name of the durable subscriber to run")
+		interval       = flag.Duration("interval", time.Second, "polling interval when there are no new events")
+		batchSize      = flag.Int("batch", 100, "maximum number of events processed per polling")
+		stopTimeout    = flag.Duration("stop-timeout", 30*time.Second, "maximum time to wait for the current batch on shutdown")
+	)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var logger system.ILogger
+	app := di.NewApp(
+		fx.StopTimeout(*stopTimeout),
+		fx.Invoke(func(params subscriberParams, l system.ILogger) {
+			logger = l
+			subscriber := findSubscriber(params.Subscribers, *subscriberName)
+			if subscriber == nil {
+				panic(fmt.Errorf("unknown subscriber: %q (available: %v)", *subscriberName, subscriberNames(params.Subscribers)))
+			}
+
+			// 停止時は処理中のバッチの完了を待ってから、DB接続などを閉じる
+			params.Lifecycle.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					params.Runner.Start(subscriber, *interval, *batchSize)
+					return nil
+				},
+				OnStop: params.Runner.Stop,
+			})
+		}),
+	)
+
+	err := app.Start(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	<-ctx.Done()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancel()
+	if err := app.Stop(stopCtx); err != nil {
+		logger.WarnWithError(nil, err, nil)
+	}
+	logger.Flush()
+}
+
+func findSubscriber(subscribers []event.ISubscriber, name string) event.ISubscriber {
+	for _, s := range subscribers {
+		if s.SubscriberName() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func subscriberNames(subscribers []event.ISubscriber) []string {
+	names := make([]string, 0, len(subscribers))
+	for _, s := range subscribers {
+		names = append(names, s.SubscriberName())
+	}
+	return names
+}
@@ -2,29 +2,36 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"path/filepath"
 
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
 	"github.com/t-kuni/cqrs-example/di"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
 	"github.com/t-kuni/cqrs-example/domain/service"
 	"go.uber.org/fx"
 )
 
 func main() {
-	godotenv.Load(filepath.Join(".env"))
+	// 実行ごとのログを追跡できるよう、全てのログに実行IDを付与する
+	runID := uuid.NewString()
+	ctx := correlation.WithRunID(context.Background(), runID)
 
-	ctx := context.Background()
-	app := di.NewApp(fx.Invoke(func(transferService service.IProductTransferService) {
-		fmt.Println("Starting product transfer to OpenSearch...")
+	app := di.NewApp(
+		fx.Decorate(func(logger system.ILogger) system.ILogger {
+			return logger.WithFields(map[string]interface{}{"run_id": runID})
+		}),
+		fx.Invoke(func(transferService service.IProductTransferService, logger system.ILogger) {
+			logger.SimpleInfoF("Starting product transfer to OpenSearch...")
 
-		err := transferService.TransferAllProducts(ctx)
-		if err != nil {
-			panic(fmt.Errorf("failed to transfer products: %w", err))
-		}
+			err := transferService.TransferAllProducts(ctx)
+			if err != nil {
+				logger.SimpleFatal(eris.Wrap(err, "failed to transfer products"), nil)
+			}
 
-		fmt.Println("Product transfer completed successfully!")
-	}))
+			logger.SimpleInfoF("Product transfer completed successfully!")
+		}),
+	)
 
 	defer app.Stop(ctx)
 	err := app.Start(ctx)
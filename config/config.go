@@ -0,0 +1,352 @@
+package config
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rotisserie/eris"
+	"github.com/sirupsen/logrus"
+)
+
+// EnvProduction は 本番環境の APP_ENV です
+const EnvProduction = "production"
+
+const (
+	// TracesExporterNone は スパンをエクスポートしない設定です
+	TracesExporterNone = "none"
+	// TracesExporterOtlp は OTLP/HTTP でスパンを送信する設定です
+	TracesExporterOtlp = "otlp"
+	// TracesExporterStdout は 標準出力にスパンを出力する設定です
+	TracesExporterStdout = "stdout"
+)
+
+const (
+	// ProductWriteModelCrud は products テーブルを直接更新する書き込みモデルです
+	ProductWriteModelCrud = "crud"
+	// ProductWriteModelEventSourcing は イベントソーシングの書き込みモデルです
+	ProductWriteModelEventSourcing = "event_sourcing"
+)
+
+// Config は 環境変数から読み込んだアプリケーションの設定です
+type Config struct {
+	App        App
+	Server     Server
+	DB         DB
+	OpenSearch OpenSearch
+	Log        Log
+	Sync       Sync
+	Auth       Auth
+	Tracing    Tracing
+}
+
+// App は アプリケーション全体の設定です
+type App struct {
+	// Env は 実行環境（APP_ENV、例: local, test, production）です
+	Env string
+}
+
+// Server は APIサーバの設定です
+type Server struct {
+	// ShutdownDrainTimeout は シャットダウン時に処理中のリクエストの完了を待つ時間（SHUTDOWN_DRAIN_TIMEOUT）です
+	ShutdownDrainTimeout time.Duration
+}
+
+// DB は MySQL の接続設定です
+type DB struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	Database string
+	// ReplicaHost は 参照系のクエリを振り分けるリードレプリカのホスト（DB_REPLICA_HOST）です
+	// 未指定の場合は全てのクエリをプライマリに送ります
+	ReplicaHost string
+	// ReplicaPort は リードレプリカのポート（DB_REPLICA_PORT、未指定の場合は DB_PORT）です
+	ReplicaPort int
+	Pool        Pool
+}
+
+// Pool は コネクションプールの設定です（プライマリとリードレプリカのそれぞれに適用します）
+type Pool struct {
+	// MaxOpenConns は 最大接続数（DB_MAX_OPEN_CONNS、0 は無制限）です
+	MaxOpenConns int
+	// MaxIdleConns は アイドル状態で保持する最大接続数（DB_MAX_IDLE_CONNS）です
+	MaxIdleConns int
+	// ConnMaxLifetime は 接続を再利用する最大時間（DB_CONN_MAX_LIFETIME、0 は無制限）です
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime は アイドル状態の接続を保持する最大時間（DB_CONN_MAX_IDLE_TIME、0 は無制限）です
+	ConnMaxIdleTime time.Duration
+}
+
+// DSN は プライマリに接続する go-sql-driver/mysql の接続文字列を返します
+func (c DB) DSN() string {
+	return c.dsn(c.Host, c.Port)
+}
+
+// HasReplica は リードレプリカが設定されているかどうかを返します
+func (c DB) HasReplica() bool {
+	return c.ReplicaHost != ""
+}
+
+// ReplicaDSN は リードレプリカに接続する go-sql-driver/mysql の接続文字列を返します
+func (c DB) ReplicaDSN() string {
+	return c.dsn(c.ReplicaHost, c.ReplicaPort)
+}
+
+func (c DB) dsn(host string, port int) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", c.User, c.Password, host, port, c.Database)
+}
+
+// OpenSearch は OpenSearch の接続設定です
+type OpenSearch struct {
+	// Origin は 接続先のURL（OPENSEARCH_ORIGIN）です
+	Origin string
+}
+
+// Log は ログ出力の設定です
+type Log struct {
+	// Level は 出力するログのレベル（LOG_LEVEL）です
+	Level logrus.Level
+}
+
+// Sync は 書き込みモデルと OpenSearch への同期の設定です
+type Sync struct {
+	// ProductWriteModel は product の書き込みモデル（PRODUCT_WRITE_MODEL）です
+	ProductWriteModel string
+	// ReadinessMaxProjectionLag は /readyz が down になる永続購読者の未処理のイベントの件数（READINESS_MAX_PROJECTION_LAG）です
+	ReadinessMaxProjectionLag int
+}
+
+// Auth は JWT の検証の設定です
+// 鍵は少なくとも1つ必要です（APIサーバの起動時に確認します）
+type Auth struct {
+	// HS256Secret は HS256 の共通鍵（JWT_HS256_SECRET）です
+	HS256Secret string
+	// RS256PublicKey は RS256 の公開鍵の PEM（JWT_RS256_PUBLIC_KEY、改行は \n でも指定できます）です
+	RS256PublicKey string
+	// JWKSFile は RS256 の公開鍵を持つ JWKS ファイルのパス（JWT_JWKS_FILE）です
+	JWKSFile string
+	// Issuer は 要求する iss クレーム（JWT_ISSUER、未指定の場合は検証しない）です
+	Issuer string
+	// Audience は 要求する aud クレーム（JWT_AUDIENCE、未指定の場合は検証しない）です
+	Audience string
+}
+
+// Tracing は OpenTelemetry によるトレースの設定です
+// 送信先やサンプリングは OTEL_EXPORTER_OTLP_ENDPOINT・OTEL_TRACES_SAMPLER などの標準の環境変数で指定します
+type Tracing struct {
+	// ServiceName は サービス名（OTEL_SERVICE_NAME）です
+	ServiceName string
+	// Exporter は スパンのエクスポータ（OTEL_TRACES_EXPORTER、none・otlp・stdout）です
+	Exporter string
+}
+
+// ValidationError は 不足または不正な設定の一覧を持つエラーです
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// NewConfig は カレントディレクトリの .env と環境変数から設定を読み込みます
+func NewConfig() (*Config, error) {
+	return Load(".env")
+}
+
+// Load は 指定した .env ファイルと環境変数から設定を読み込んで検証します
+// 既に設定されている環境変数は .env ファイルの値で上書きしません。存在しない .env ファイルは無視します
+// 不足または不正な設定がある場合は、その全てを列挙した ValidationError を返します
+func Load(envFiles ...string) (*Config, error) {
+	for _, file := range envFiles {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		if err := godotenv.Load(file); err != nil {
+			return nil, eris.Wrapf(err, "failed to load %s", file)
+		}
+	}
+
+	r := &envReader{}
+	dbPort := r.port("DB_PORT", 3306)
+	cfg := &Config{
+		App: App{
+			Env: r.string("APP_ENV", "local"),
+		},
+		Server: Server{
+			ShutdownDrainTimeout: r.duration("SHUTDOWN_DRAIN_TIMEOUT", 20*time.Second),
+		},
+		DB: DB{
+			User:        r.required("DB_USER"),
+			Password:    r.string("DB_PASSWORD", ""),
+			Host:        r.required("DB_HOST"),
+			Port:        dbPort,
+			Database:    r.required("DB_DATABASE"),
+			ReplicaHost: r.string("DB_REPLICA_HOST", ""),
+			ReplicaPort: r.port("DB_REPLICA_PORT", dbPort),
+			Pool: Pool{
+				MaxOpenConns:    r.nonNegativeInt("DB_MAX_OPEN_CONNS", 25),
+				MaxIdleConns:    r.nonNegativeInt("DB_MAX_IDLE_CONNS", 25),
+				ConnMaxLifetime: r.duration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+				ConnMaxIdleTime: r.duration("DB_CONN_MAX_IDLE_TIME", 0),
+			},
+		},
+		OpenSearch: OpenSearch{
+			Origin: r.url("OPENSEARCH_ORIGIN"),
+		},
+		Log: Log{
+			Level: r.logLevel("LOG_LEVEL", logrus.InfoLevel),
+		},
+		Sync: Sync{
+			ProductWriteModel:         r.oneOf("PRODUCT_WRITE_MODEL", ProductWriteModelCrud, ProductWriteModelCrud, ProductWriteModelEventSourcing),
+			ReadinessMaxProjectionLag: r.nonNegativeInt("READINESS_MAX_PROJECTION_LAG", 10000),
+		},
+		Auth: Auth{
+			HS256Secret:    r.string("JWT_HS256_SECRET", ""),
+			RS256PublicKey: r.pem("JWT_RS256_PUBLIC_KEY"),
+			JWKSFile:       r.file("JWT_JWKS_FILE"),
+			Issuer:         r.string("JWT_ISSUER", ""),
+			Audience:       r.string("JWT_AUDIENCE", ""),
+		},
+		Tracing: Tracing{
+			ServiceName: r.string("OTEL_SERVICE_NAME", "cqrs-example"),
+			Exporter:    r.oneOf("OTEL_TRACES_EXPORTER", TracesExporterNone, TracesExporterNone, TracesExporterOtlp, TracesExporterStdout),
+		},
+	}
+
+	if len(r.problems) > 0 {
+		return nil, &ValidationError{Problems: r.problems}
+	}
+	return cfg, nil
+}
+
+// envReader は 環境変数を読み込み、不足または不正な値を problems に蓄積します
+// 最初のエラーで止めずに全ての問題を報告するために使用します
+type envReader struct {
+	problems []string
+}
+
+func (r *envReader) addProblem(key string, format string, args ...interface{}) {
+	r.problems = append(r.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (r *envReader) string(key string, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func (r *envReader) required(key string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		r.addProblem(key, "is required")
+	}
+	return v
+}
+
+func (r *envReader) oneOf(key string, defaultValue string, allowed ...string) string {
+	v := r.string(key, defaultValue)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	r.addProblem(key, "must be one of %s (got %q)", strings.Join(allowed, ", "), v)
+	return defaultValue
+}
+
+func (r *envReader) port(key string, defaultValue int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+	port, err := strconv.Atoi(v)
+	if err != nil || port < 1 || port > 65535 {
+		r.addProblem(key, "must be a port number (got %q)", v)
+		return defaultValue
+	}
+	return port
+}
+
+func (r *envReader) nonNegativeInt(key string, defaultValue int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		r.addProblem(key, "must be a non-negative integer (got %q)", v)
+		return defaultValue
+	}
+	return n
+}
+
+func (r *envReader) duration(key string, defaultValue time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		r.addProblem(key, "must be a duration such as 30s (got %q)", v)
+		return defaultValue
+	}
+	return d
+}
+
+func (r *envReader) url(key string) string {
+	v := r.required(key)
+	if v == "" {
+		return v
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		r.addProblem(key, "must be an absolute URL such as http://localhost:9200 (got %q)", v)
+	}
+	return v
+}
+
+func (r *envReader) logLevel(key string, defaultValue logrus.Level) logrus.Level {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+	level, err := logrus.ParseLevel(v)
+	if err != nil {
+		r.addProblem(key, "must be one of panic, fatal, error, warn, info, debug, trace (got %q)", v)
+		return defaultValue
+	}
+	return level
+}
+
+// pem は PEM 形式の値を読み込みます。改行を \n と書いた1行の値も受け付けます
+func (r *envReader) pem(key string) string {
+	v := strings.ReplaceAll(os.Getenv(key), `\n`, "\n")
+	if v == "" {
+		return v
+	}
+	if block, _ := pem.Decode([]byte(v)); block == nil {
+		r.addProblem(key, "must be a PEM encoded key")
+	}
+	return v
+}
+
+// file は 存在するファイルのパスを読み込みます
+func (r *envReader) file(key string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return v
+	}
+	if info, err := os.Stat(v); err != nil || info.IsDir() {
+		r.addProblem(key, "must be a path to an existing file (got %q)", v)
+	}
+	return v
+}
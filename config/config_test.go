@@ -0,0 +1,159 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+)
+
+// setEnv は 設定に関する環境変数を全て未設定にしてから values を設定します
+func setEnv(t *testing.T, values map[string]string) {
+	for _, key := range []string{
+		"APP_ENV", "SHUTDOWN_DRAIN_TIMEOUT",
+		"DB_USER", "DB_PASSWORD", "DB_HOST", "DB_PORT", "DB_DATABASE", "DB_REPLICA_HOST", "DB_REPLICA_PORT",
+		"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME",
+		"OPENSEARCH_ORIGIN", "LOG_LEVEL", "PRODUCT_WRITE_MODEL", "READINESS_MAX_PROJECTION_LAG",
+		"JWT_HS256_SECRET", "JWT_RS256_PUBLIC_KEY", "JWT_JWKS_FILE", "JWT_ISSUER", "JWT_AUDIENCE",
+		"OTEL_SERVICE_NAME", "OTEL_TRACES_EXPORTER",
+	} {
+		// t.Setenv でテスト終了後に元の値へ戻るようにしてから未設定にする
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+		if v, ok := values[key]; ok {
+			t.Setenv(key, v)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("環境変数から設定を読み込み、未指定の項目は既定値になること", func(t *testing.T) {
+		setEnv(t, map[string]string{
+			"DB_USER":           "root",
+			"DB_PASSWORD":       "secret",
+			"DB_HOST":           "db",
+			"DB_DATABASE":       "example",
+			"OPENSEARCH_ORIGIN": "http://opensearch:9200",
+			"LOG_LEVEL":         "debug",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "local", cfg.App.Env)
+		assert.Equal(t, 20*time.Second, cfg.Server.ShutdownDrainTimeout)
+		assert.Equal(t, "root:secret@tcp(db:3306)/example?parseTime=true", cfg.DB.DSN())
+		assert.False(t, cfg.DB.HasReplica())
+		assert.Equal(t, config.Pool{MaxOpenConns: 25, MaxIdleConns: 25, ConnMaxLifetime: 5 * time.Minute}, cfg.DB.Pool)
+		assert.Equal(t, "http://opensearch:9200", cfg.OpenSearch.Origin)
+		assert.Equal(t, logrus.DebugLevel, cfg.Log.Level)
+		assert.Equal(t, config.ProductWriteModelCrud, cfg.Sync.ProductWriteModel)
+		assert.Equal(t, 10000, cfg.Sync.ReadinessMaxProjectionLag)
+		assert.Equal(t, config.Auth{}, cfg.Auth)
+		assert.Equal(t, config.Tracing{ServiceName: "cqrs-example", Exporter: config.TracesExporterNone}, cfg.Tracing)
+	})
+
+	t.Run("JWTとトレースの設定を読み込み、1行で指定したPEMの改行を復元すること", func(t *testing.T) {
+		jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+		assert.NoError(t, os.WriteFile(jwksFile, []byte(`{"keys":[]}`), 0o600))
+		setEnv(t, map[string]string{
+			"DB_USER":              "root",
+			"DB_HOST":              "db",
+			"DB_DATABASE":          "example",
+			"OPENSEARCH_ORIGIN":    "http://opensearch:9200",
+			"JWT_HS256_SECRET":     "secret",
+			"JWT_RS256_PUBLIC_KEY": `-----BEGIN PUBLIC KEY-----\nAQID\n-----END PUBLIC KEY-----`,
+			"JWT_JWKS_FILE":        jwksFile,
+			"JWT_ISSUER":           "https://issuer.example.com",
+			"JWT_AUDIENCE":         "cqrs-example",
+			"OTEL_SERVICE_NAME":    "cqrs-example-api",
+			"OTEL_TRACES_EXPORTER": "otlp",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.Equal(t, config.Auth{
+			HS256Secret:    "secret",
+			RS256PublicKey: "-----BEGIN PUBLIC KEY-----\nAQID\n-----END PUBLIC KEY-----",
+			JWKSFile:       jwksFile,
+			Issuer:         "https://issuer.example.com",
+			Audience:       "cqrs-example",
+		}, cfg.Auth)
+		assert.Equal(t, config.Tracing{ServiceName: "cqrs-example-api", Exporter: config.TracesExporterOtlp}, cfg.Tracing)
+	})
+
+	t.Run("リードレプリカとコネクションプールの設定を読み込むこと", func(t *testing.T) {
+		setEnv(t, map[string]string{
+			"DB_USER":               "root",
+			"DB_HOST":               "db",
+			"DB_PORT":               "3307",
+			"DB_DATABASE":           "example",
+			"DB_REPLICA_HOST":       "db-replica",
+			"OPENSEARCH_ORIGIN":     "http://opensearch:9200",
+			"DB_MAX_OPEN_CONNS":     "50",
+			"DB_MAX_IDLE_CONNS":     "10",
+			"DB_CONN_MAX_LIFETIME":  "30m",
+			"DB_CONN_MAX_IDLE_TIME": "1m",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.True(t, cfg.DB.HasReplica())
+		// ポートを指定しない場合はプライマリと同じポートを使用する
+		assert.Equal(t, "root:@tcp(db-replica:3307)/example?parseTime=true", cfg.DB.ReplicaDSN())
+		assert.Equal(t, config.Pool{MaxOpenConns: 50, MaxIdleConns: 10, ConnMaxLifetime: 30 * time.Minute, ConnMaxIdleTime: time.Minute}, cfg.DB.Pool)
+	})
+
+	t.Run(".envファイルの値は既に設定されている環境変数を上書きしないこと", func(t *testing.T) {
+		setEnv(t, map[string]string{"DB_HOST": "from-env"})
+		envFile := filepath.Join(t.TempDir(), ".env")
+		assert.NoError(t, os.WriteFile(envFile, []byte("DB_USER=root\nDB_HOST=from-file\nDB_DATABASE=example\nOPENSEARCH_ORIGIN=http://opensearch:9200\n"), 0o600))
+
+		cfg, err := config.Load(envFile, filepath.Join(t.TempDir(), "missing.env"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "root", cfg.DB.User)
+		assert.Equal(t, "from-env", cfg.DB.Host)
+	})
+
+	t.Run("不足または不正な設定を全て列挙したエラーを返すこと", func(t *testing.T) {
+		setEnv(t, map[string]string{
+			"DB_HOST":                      "db",
+			"DB_PORT":                      "abc",
+			"OPENSEARCH_ORIGIN":            "opensearch:9200",
+			"LOG_LEVEL":                    "verbose",
+			"SHUTDOWN_DRAIN_TIMEOUT":       "10",
+			"PRODUCT_WRITE_MODEL":          "cqrs",
+			"READINESS_MAX_PROJECTION_LAG": "-1",
+			"JWT_RS256_PUBLIC_KEY":         "not-a-pem",
+			"JWT_JWKS_FILE":                "/no/such/jwks.json",
+			"OTEL_TRACES_EXPORTER":         "jaeger",
+		})
+
+		_, err := config.Load()
+
+		var validationErr *config.ValidationError
+		assert.True(t, eris.As(err, &validationErr))
+		assert.ElementsMatch(t, []string{
+			`SHUTDOWN_DRAIN_TIMEOUT: must be a duration such as 30s (got "10")`,
+			`DB_USER: is required`,
+			`DB_PORT: must be a port number (got "abc")`,
+			`DB_DATABASE: is required`,
+			`OPENSEARCH_ORIGIN: must be an absolute URL such as http://localhost:9200 (got "opensearch:9200")`,
+			`LOG_LEVEL: must be one of panic, fatal, error, warn, info, debug, trace (got "verbose")`,
+			`PRODUCT_WRITE_MODEL: must be one of crud, event_sourcing (got "cqrs")`,
+			`READINESS_MAX_PROJECTION_LAG: must be a non-negative integer (got "-1")`,
+			`JWT_RS256_PUBLIC_KEY: must be a PEM encoded key`,
+			`JWT_JWKS_FILE: must be a path to an existing file (got "/no/such/jwks.json")`,
+			`OTEL_TRACES_EXPORTER: must be one of none, otlp, stdout (got "jaeger")`,
+		}, validationErr.Problems)
+		assert.Contains(t, err.Error(), "invalid configuration:\n  - ")
+	})
+}
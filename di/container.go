@@ -1,6 +1,12 @@
 package di
 
 import (
+	"github.com/t-kuni/cqrs-example/application/handler"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/eventsourcing"
 	"github.com/t-kuni/cqrs-example/domain/service"
 	customErrors "github.com/t-kuni/cqrs-example/errors"
 	"github.com/t-kuni/cqrs-example/infrastructure/api"
@@ -18,27 +24,88 @@ func NewApp(opts ...fx.Option) *fx.App {
 		//}),
 		fx.Provide(
 
+			// Config
+			config.NewConfig,
+
 			// Validator
 			validator.NewCustomValidator,
 
 			// Middleware
+			middleware.NewHealthCheck,
+			middleware.NewDrain,
+			middleware.NewMetricsEndpoint,
+			middleware.NewTracing,
+			middleware.NewRequestID,
 			middleware.NewRecover,
 			middleware.NewAccessLog,
+			middleware.NewIdempotency,
+			middleware.NewTenantScope,
+			middleware.NewAuthentication,
 
 			// Handler
-			// handler.NewGetUsers,
+			handler.NewGetUsers,
+			handler.NewGetUsersID,
+			handler.NewPostUsers,
+			handler.NewPutUsers,
+			handler.NewDeleteUsers,
+			handler.NewGetTenants,
+			handler.NewGetTenantsID,
+			handler.NewPostTenants,
+			handler.NewPutTenants,
+			handler.NewDeleteTenants,
+			handler.NewGetCategories,
+			handler.NewGetCategoriesID,
+			handler.NewPostCategories,
+			handler.NewPutCategories,
+			handler.NewDeleteCategories,
+			handler.NewGetProducts,
+			handler.NewPostProducts,
+			handler.NewPutProducts,
+			handler.NewDeleteProducts,
+
+			// Command
+			command.NewCommandBus,
+			command.NewProductCommandHandler,
+			command.NewEventSourcedProductCommandHandler,
+			command.SelectProductCommandHandler,
+			command.NewCategoryCommandHandler,
+
+			// Event
+			fx.Annotate(event.NewDispatcher, fx.ParamTags(`group:"eventSubscribers"`)),
+			event.NewStore,
+			event.NewDurableProcessor,
+			event.NewDurableRunner,
+
+			// Event Sourcing
+			eventsourcing.NewEventStore,
+			eventsourcing.NewProductRepository,
+			eventsourcing.NewProductProjector,
+
+			// Authorization
+			authz.NewAuthorizer,
 
 			// Service
 			service.NewExampleService,
 			service.NewProductTransferService,
+			service.NewProductSearchService,
+			// プロセス内の購読者はコミット直後に同期的に呼び出される
+			fx.Annotate(service.NewProductProjectionService, fx.ResultTags(`group:"eventSubscribers"`)),
+			// 永続購読者は subscribeEvents コマンドから呼び出される
+			fx.Annotate(service.NewEventAuditService, fx.ResultTags(`group:"durableEventSubscribers"`)),
+			fx.Annotate(service.NewHealthCheckService, fx.ParamTags(``, ``, ``, ``, `group:"durableEventSubscribers"`)),
 
 			// Infrastructure
 			db.NewConnector,
+			db.NewMigrator,
 			api.NewBinanceApi,
 			api.NewOpenSearchApi,
 			system.NewTimer,
 			system.NewLogger,
 			system.NewUuidGenerator,
+			system.NewJwtAuthenticator,
+			system.NewTracerProvider,
+			system.NewMetricsRegistry,
+			system.NewMetrics,
 
 			// Others
 			customErrors.NewCustomServeError,
@@ -0,0 +1,153 @@
+package aggregate
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/model"
+)
+
+// ProductAggregateType は イベントストアで product の集約を識別する名前です。
+const ProductAggregateType = "Product"
+
+// ErrProductNotFound は 存在しない、または削除済みの product を操作しようとした場合のエラーです。
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrProductAlreadyExists は 登録済みの product を再度登録しようとした場合のエラーです。
+var ErrProductAlreadyExists = errors.New("product already exists")
+
+// Product は イベントソーシングで状態を管理する product の集約です。
+// 状態はドメインイベントを順番に適用することで復元されます。
+// JSONに変換した値はスナップショットとして保存されます。
+type Product struct {
+	ID         uuid.UUID               `json:"id"`
+	TenantID   uuid.UUID               `json:"tenant_id"`
+	CategoryID uuid.UUID               `json:"category_id"`
+	Name       string                  `json:"name"`
+	Price      int64                   `json:"price"`
+	Properties model.ProductProperties `json:"properties"`
+	ListedAt   time.Time               `json:"listed_at"`
+	Deleted    bool                    `json:"deleted"`
+
+	// Version は 最後に適用したイベントのシーケンス番号です（イベントが1件もない場合は 0）
+	Version int64 `json:"version"`
+
+	changes []event.Event
+}
+
+// NewProduct は イベントが1件もない状態の Product を作成します。
+func NewProduct(id uuid.UUID) *Product {
+	return &Product{ID: id}
+}
+
+// RebuildProduct は スナップショットとその後のイベントから Product の状態を復元します。
+// スナップショットがない場合は nil を渡してください。
+func RebuildProduct(id uuid.UUID, snapshot *Product, history []event.Event) *Product {
+	p := NewProduct(id)
+	if snapshot != nil {
+		p = snapshot
+		p.changes = nil
+	}
+	for _, e := range history {
+		p.Apply(e)
+	}
+	return p
+}
+
+// Exists は product が登録済みかつ削除されていないかを返します。
+func (p *Product) Exists() bool {
+	return p.Version > 0 && !p.Deleted
+}
+
+// Create は product を登録します。
+func (p *Product) Create(tenantID, categoryID uuid.UUID, name string, price int64, properties model.ProductProperties, listedAt time.Time) error {
+	if p.Version > 0 {
+		return ErrProductAlreadyExists
+	}
+
+	p.raise(event.ProductCreated{
+		ProductID:  p.ID,
+		TenantID:   tenantID,
+		CategoryID: categoryID,
+		Name:       name,
+		Price:      price,
+		Properties: properties,
+		ListedAt:   listedAt,
+	})
+	return nil
+}
+
+// Update は product を更新します。
+// 価格が変更された場合は ProductPriceChanged も発生させます。
+func (p *Product) Update(categoryID uuid.UUID, name string, price int64, properties model.ProductProperties) error {
+	if !p.Exists() {
+		return ErrProductNotFound
+	}
+
+	oldPrice := p.Price
+	p.raise(event.ProductUpdated{
+		ProductID:  p.ID,
+		CategoryID: categoryID,
+		Name:       name,
+		Properties: properties,
+	})
+	if oldPrice != price {
+		p.raise(event.ProductPriceChanged{
+			ProductID: p.ID,
+			OldPrice:  oldPrice,
+			NewPrice:  price,
+		})
+	}
+	return nil
+}
+
+// Delete は product を削除します。
+func (p *Product) Delete() error {
+	if !p.Exists() {
+		return ErrProductNotFound
+	}
+
+	p.raise(event.ProductDeleted{ProductID: p.ID})
+	return nil
+}
+
+// Apply は イベントを1件適用して状態を進めます。
+// イベントストアから読み出したイベントの再生と、新しく発生させたイベントの適用の両方で使用します。
+func (p *Product) Apply(e event.Event) {
+	switch ev := e.(type) {
+	case event.ProductCreated:
+		p.TenantID = ev.TenantID
+		p.CategoryID = ev.CategoryID
+		p.Name = ev.Name
+		p.Price = ev.Price
+		p.Properties = ev.Properties
+		p.ListedAt = ev.ListedAt
+		p.Deleted = false
+	case event.ProductUpdated:
+		p.CategoryID = ev.CategoryID
+		p.Name = ev.Name
+		p.Properties = ev.Properties
+	case event.ProductPriceChanged:
+		p.Price = ev.NewPrice
+	case event.ProductDeleted:
+		p.Deleted = true
+	}
+	p.Version++
+}
+
+// Changes は 永続化されていない新しいイベントを返します。
+func (p *Product) Changes() []event.Event {
+	return p.changes
+}
+
+// ClearChanges は 新しいイベントを永続化済みとして破棄します。
+func (p *Product) ClearChanges() {
+	p.changes = nil
+}
+
+func (p *Product) raise(e event.Event) {
+	p.Apply(e)
+	p.changes = append(p.changes, e)
+}
@@ -0,0 +1,79 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/model"
+)
+
+func TestProduct(t *testing.T) {
+	id := uuid.MustParse("b3c9e1a4-0000-4000-8000-000000000001")
+	tenantID := uuid.MustParse("b3c9e1a4-0000-4000-8000-000000000002")
+	categoryID := uuid.MustParse("b3c9e1a4-0000-4000-8000-000000000003")
+	listedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("価格を変更した場合はProductUpdatedとProductPriceChangedが発生すること", func(t *testing.T) {
+		p := NewProduct(id)
+		assert.NoError(t, p.Create(tenantID, categoryID, "Shirt", 1000, model.ProductProperties{}, listedAt))
+		p.ClearChanges()
+
+		assert.NoError(t, p.Update(categoryID, "T-Shirt", 1200, model.ProductProperties{}))
+
+		assert.Equal(t, []event.Event{
+			event.ProductUpdated{ProductID: id, CategoryID: categoryID, Name: "T-Shirt"},
+			event.ProductPriceChanged{ProductID: id, OldPrice: 1000, NewPrice: 1200},
+		}, p.Changes())
+		assert.Equal(t, int64(3), p.Version)
+		assert.Equal(t, int64(1200), p.Price)
+	})
+
+	t.Run("イベントから状態を復元できること", func(t *testing.T) {
+		history := []event.Event{
+			event.ProductCreated{ProductID: id, TenantID: tenantID, CategoryID: categoryID, Name: "Shirt", Price: 1000, ListedAt: listedAt},
+			event.ProductUpdated{ProductID: id, CategoryID: categoryID, Name: "T-Shirt"},
+			event.ProductPriceChanged{ProductID: id, OldPrice: 1000, NewPrice: 1200},
+		}
+
+		p := RebuildProduct(id, nil, history)
+
+		assert.True(t, p.Exists())
+		assert.Equal(t, "T-Shirt", p.Name)
+		assert.Equal(t, int64(1200), p.Price)
+		assert.Equal(t, listedAt, p.ListedAt)
+		assert.Equal(t, int64(3), p.Version)
+		assert.Empty(t, p.Changes())
+	})
+
+	t.Run("スナップショットとその後のイベントから状態を復元できること", func(t *testing.T) {
+		snapshot := &Product{ID: id, TenantID: tenantID, CategoryID: categoryID, Name: "Shirt", Price: 1000, ListedAt: listedAt, Version: 50}
+
+		p := RebuildProduct(id, snapshot, []event.Event{
+			event.ProductPriceChanged{ProductID: id, OldPrice: 1000, NewPrice: 900},
+		})
+
+		assert.Equal(t, int64(900), p.Price)
+		assert.Equal(t, int64(51), p.Version)
+	})
+
+	t.Run("削除済みのproductは更新できないこと", func(t *testing.T) {
+		p := RebuildProduct(id, nil, []event.Event{
+			event.ProductCreated{ProductID: id, TenantID: tenantID, CategoryID: categoryID, Name: "Shirt", Price: 1000, ListedAt: listedAt},
+			event.ProductDeleted{ProductID: id},
+		})
+
+		assert.ErrorIs(t, p.Update(categoryID, "T-Shirt", 1200, model.ProductProperties{}), ErrProductNotFound)
+		assert.ErrorIs(t, p.Delete(), ErrProductNotFound)
+	})
+
+	t.Run("登録済みのproductは再度登録できないこと", func(t *testing.T) {
+		p := RebuildProduct(id, nil, []event.Event{
+			event.ProductCreated{ProductID: id, TenantID: tenantID, CategoryID: categoryID, Name: "Shirt", Price: 1000, ListedAt: listedAt},
+		})
+
+		assert.ErrorIs(t, p.Create(tenantID, categoryID, "Shirt", 1000, model.ProductProperties{}, listedAt), ErrProductAlreadyExists)
+	})
+}
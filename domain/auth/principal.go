@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Principal は 認証済みのリクエストの主体です
+type Principal struct {
+	// UserID は トークンの sub クレームのユーザIDです
+	UserID uuid.UUID
+	// TenantIDs は トークンの tenant_ids クレームの、主体がアクセスできるテナントのIDです
+	TenantIDs []uuid.UUID
+}
+
+// HasTenant は 主体が指定したテナントにアクセスできるかどうかを返します
+func (p *Principal) HasTenant(tenantID uuid.UUID) bool {
+	for _, id := range p.TenantIDs {
+		if id == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal は 認証済みの主体を設定したコンテキストを返します
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext は コンテキストの認証済みの主体を返します。
+// 認証されていない場合は ok が false になります。
+func FromContext(ctx context.Context) (principal *Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,35 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+)
+
+// IAuthorizer は 認証済みの主体のテナントに対する操作の権限を確認するインターフェースです。
+// 書き込み系のハンドラはコマンドの実行前にこれを使用して権限を確認します（ent の privacy ルールでも同じポリシーを確認します）。
+type IAuthorizer interface {
+	// Authorize は 主体に操作の権限が無い場合に types.ForbiddenError を返します。
+	// 認証されていないコンテキスト（バッチ処理など）と、存在しないテナントは確認の対象外です。
+	Authorize(ctx context.Context, tenantID uuid.UUID, action Action) error
+}
+
+// Authorizer は IAuthorizer の実装です。
+type Authorizer struct {
+	DBConnector db.IConnector
+}
+
+// NewAuthorizer は Authorizer の新しいインスタンスを作成します。
+func NewAuthorizer(conn db.IConnector) (IAuthorizer, error) {
+	return &Authorizer{
+		DBConnector: conn,
+	}, nil
+}
+
+// Authorize は 主体に操作の権限が無い場合に types.ForbiddenError を返します。
+func (a *Authorizer) Authorize(ctx context.Context, tenantID uuid.UUID, action Action) error {
+	return eris.Wrap(authorize(ctx, a.DBConnector.GetEnt(), tenantID, action), "")
+}
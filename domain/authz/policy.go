@@ -0,0 +1,100 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/membership"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// Action は 認可の対象となるテナントに対する操作です
+type Action string
+
+const (
+	// ActionWriteProducts は テナントの product の登録・更新・削除です
+	ActionWriteProducts Action = "products:write"
+	// ActionUpdateTenant は テナントの更新です
+	ActionUpdateTenant Action = "tenant:update"
+	// ActionDeleteTenant は テナントの削除です
+	ActionDeleteTenant Action = "tenant:delete"
+	// ActionTransferTenant は テナントの owner の変更です
+	ActionTransferTenant Action = "tenant:transfer"
+	// ActionManageMembers は テナントの membership の登録・更新・削除です
+	ActionManageMembers Action = "members:manage"
+)
+
+// policies は 操作ごとに許可するロールです
+var policies = map[Action][]membership.Role{
+	ActionWriteProducts:  {membership.RoleOwner, membership.RoleAdmin, membership.RoleMember},
+	ActionUpdateTenant:   {membership.RoleOwner, membership.RoleAdmin},
+	ActionDeleteTenant:   {membership.RoleOwner},
+	ActionTransferTenant: {membership.RoleOwner},
+	ActionManageMembers:  {membership.RoleOwner, membership.RoleAdmin},
+}
+
+// IsAllowed は ロールに操作が許可されているかどうかを返します
+func IsAllowed(role membership.Role, action Action) bool {
+	for _, allowed := range policies[action] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize は コンテキストの主体がテナントに対する操作を行えるかどうかを確認し、権限が無い場合は types.ForbiddenError を返します
+// 認証されていないコンテキスト（バッチ処理など）と、存在しないテナントは確認の対象外です
+func authorize(ctx context.Context, client *ent.Client, tenantID uuid.UUID, action Action) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	role, exists, err := resolveRole(ctx, client, principal.UserID, tenantID)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if !exists {
+		return nil
+	}
+
+	if role == "" || !IsAllowed(role, action) {
+		return eris.Wrap(types.NewForbiddenError("permission denied", map[string]interface{}{
+			"userId":   principal.UserID.String(),
+			"tenantId": tenantID.String(),
+			"action":   string(action),
+		}), "")
+	}
+	return nil
+}
+
+// resolveRole は ユーザのテナントに対するロールを返します
+// テナントの owner_id のユーザは owner、それ以外は membership のロールです（membership が無い場合は空文字）
+// テナントが存在しない場合は exists が false になります
+func resolveRole(ctx context.Context, client *ent.Client, userID uuid.UUID, tenantID uuid.UUID) (role membership.Role, exists bool, err error) {
+	t, err := client.Tenant.Get(ctx, tenantID)
+	if ent.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, eris.Wrap(err, "")
+	}
+	if t.OwnerID == userID {
+		return membership.RoleOwner, true, nil
+	}
+
+	m, err := client.Membership.Query().
+		Where(membership.TenantID(tenantID), membership.UserID(userID)).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, eris.Wrap(err, "")
+	}
+	return m.Role, true, nil
+}
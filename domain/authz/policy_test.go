@@ -0,0 +1,35 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/ent/membership"
+)
+
+func TestIsAllowed(t *testing.T) {
+	cases := []struct {
+		role    membership.Role
+		action  authz.Action
+		allowed bool
+	}{
+		{membership.RoleOwner, authz.ActionWriteProducts, true},
+		{membership.RoleAdmin, authz.ActionWriteProducts, true},
+		{membership.RoleMember, authz.ActionWriteProducts, true},
+		{membership.RoleOwner, authz.ActionUpdateTenant, true},
+		{membership.RoleAdmin, authz.ActionUpdateTenant, true},
+		{membership.RoleMember, authz.ActionUpdateTenant, false},
+		{membership.RoleOwner, authz.ActionDeleteTenant, true},
+		{membership.RoleAdmin, authz.ActionDeleteTenant, false},
+		{membership.RoleAdmin, authz.ActionTransferTenant, false},
+		{membership.RoleMember, authz.ActionManageMembers, false},
+		{"", authz.ActionWriteProducts, false},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.role)+" "+string(c.action), func(t *testing.T) {
+			assert.Equal(t, c.allowed, authz.IsAllowed(c.role, c.action))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package authz
+
+import (
+	"context"
+
+	entgo "entgo.io/ent"
+	"entgo.io/ent/privacy"
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/membership"
+	"github.com/t-kuni/cqrs-example/ent/product"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// ProductMutationRule は product の登録・更新・削除を、対象テナントで ActionWriteProducts を許可されたロールに限定する privacy ルールです
+// 認証されていないコンテキストは判定せずに次のルールに委ねます
+func ProductMutationRule() privacy.MutationRule {
+	return privacy.MutationRuleFunc(func(ctx context.Context, m entgo.Mutation) error {
+		pm, ok := m.(*ent.ProductMutation)
+		if !ok {
+			return privacy.Skip
+		}
+		if _, authenticated := auth.FromContext(ctx); !authenticated {
+			return privacy.Skip
+		}
+
+		tenantIDs, err := productMutationTenantIDs(ctx, pm)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		for _, tenantID := range tenantIDs {
+			if err := authorize(ctx, pm.Client(), tenantID, ActionWriteProducts); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+		return privacy.Skip
+	})
+}
+
+// TenantMutationRule は テナントの更新・削除・owner の変更をそれぞれ許可されたロールに限定し、
+// 他のユーザを owner とするテナントの登録を拒否する privacy ルールです
+func TenantMutationRule() privacy.MutationRule {
+	return privacy.MutationRuleFunc(func(ctx context.Context, m entgo.Mutation) error {
+		tm, ok := m.(*ent.TenantMutation)
+		if !ok {
+			return privacy.Skip
+		}
+		principal, authenticated := auth.FromContext(ctx)
+		if !authenticated {
+			return privacy.Skip
+		}
+
+		if tm.Op().Is(ent.OpCreate) {
+			if ownerID, exists := tm.OwnerID(); exists && ownerID != principal.UserID {
+				return eris.Wrap(types.NewForbiddenError("permission denied", map[string]interface{}{
+					"userId":  principal.UserID.String(),
+					"ownerId": ownerID.String(),
+				}), "")
+			}
+			return privacy.Skip
+		}
+
+		action := ActionUpdateTenant
+		if tm.Op().Is(ent.OpDelete | ent.OpDeleteOne) {
+			action = ActionDeleteTenant
+		}
+
+		ids, err := tm.IDs(ctx)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		for _, id := range ids {
+			if err := authorize(ctx, tm.Client(), id, action); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+
+		// owner の変更は owner のみに許可する
+		if ownerID, exists := tm.OwnerID(); exists && tm.Op().Is(ent.OpUpdateOne) {
+			oldOwnerID, err := tm.OldOwnerID(ctx)
+			if err != nil {
+				return eris.Wrap(err, "")
+			}
+			if ownerID != oldOwnerID {
+				for _, id := range ids {
+					if err := authorize(ctx, tm.Client(), id, ActionTransferTenant); err != nil {
+						return eris.Wrap(err, "")
+					}
+				}
+			}
+		}
+		return privacy.Skip
+	})
+}
+
+// MembershipMutationRule は membership の登録・更新・削除を、対象テナントで ActionManageMembers を許可されたロールに限定する privacy ルールです
+func MembershipMutationRule() privacy.MutationRule {
+	return privacy.MutationRuleFunc(func(ctx context.Context, m entgo.Mutation) error {
+		mm, ok := m.(*ent.MembershipMutation)
+		if !ok {
+			return privacy.Skip
+		}
+		if _, authenticated := auth.FromContext(ctx); !authenticated {
+			return privacy.Skip
+		}
+
+		tenantIDs := map[uuid.UUID]struct{}{}
+		if tenantID, exists := mm.TenantID(); exists {
+			tenantIDs[tenantID] = struct{}{}
+		}
+		if !mm.Op().Is(ent.OpCreate) {
+			ids, err := mm.IDs(ctx)
+			if err != nil {
+				return eris.Wrap(err, "")
+			}
+			rows, err := mm.Client().Membership.Query().Where(membership.IDIn(ids...)).All(ctx)
+			if err != nil {
+				return eris.Wrap(err, "")
+			}
+			for _, row := range rows {
+				tenantIDs[row.TenantID] = struct{}{}
+			}
+		}
+
+		for tenantID := range tenantIDs {
+			if err := authorize(ctx, mm.Client(), tenantID, ActionManageMembers); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+		return privacy.Skip
+	})
+}
+
+// productMutationTenantIDs は 更新の前後で product が属するテナントを返します
+func productMutationTenantIDs(ctx context.Context, pm *ent.ProductMutation) ([]uuid.UUID, error) {
+	tenantIDs := map[uuid.UUID]struct{}{}
+	if tenantID, exists := pm.TenantID(); exists {
+		tenantIDs[tenantID] = struct{}{}
+	}
+
+	if !pm.Op().Is(ent.OpCreate) {
+		ids, err := pm.IDs(ctx)
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		rows, err := pm.Client().Product.Query().Where(product.IDIn(ids...)).All(ctx)
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		for _, row := range rows {
+			tenantIDs[row.TenantID] = struct{}{}
+		}
+	}
+
+	result := make([]uuid.UUID, 0, len(tenantIDs))
+	for tenantID := range tenantIDs {
+		result = append(result, tenantID)
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package command
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/rotisserie/eris"
+)
+
+// ICommand は書き込み系の操作を表すコマンドのインターフェースです。
+// CommandName はログ出力などでコマンドを識別するために使用します。
+type ICommand interface {
+	CommandName() string
+}
+
+// HandlerFunc はコマンドを処理する関数です。
+type HandlerFunc func(ctx context.Context, cmd ICommand) (interface{}, error)
+
+// Middleware はコマンドの処理の前後に共通処理を差し込むための関数です。
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// IBus はコマンドを対応するハンドラに振り分けるコマンドバスのインターフェースです。
+// application/handler から書き込み系の処理を呼び出す際に使用します。
+type IBus interface {
+	// Dispatch は コマンドを登録済みのハンドラで処理します。
+	// ハンドラが登録されていないコマンドの場合はエラーを返します。
+	Dispatch(ctx context.Context, cmd ICommand) (interface{}, error)
+}
+
+// Bus は IBus の実装です。
+type Bus struct {
+	handlers    map[reflect.Type]HandlerFunc
+	middlewares []Middleware
+}
+
+// NewBus は Bus の新しいインスタンスを作成します。
+// middlewares は先頭に指定したものほど外側で実行されます。
+func NewBus(middlewares ...Middleware) *Bus {
+	return &Bus{
+		handlers:    make(map[reflect.Type]HandlerFunc),
+		middlewares: middlewares,
+	}
+}
+
+// Register は コマンドの型に対応するハンドラを登録します。
+// 同じ型のコマンドに対して複数回登録した場合はエラーを返します。
+func Register[C ICommand, R any](bus *Bus, handler func(ctx context.Context, cmd C) (R, error)) error {
+	var zero C
+	commandType := reflect.TypeOf(zero)
+	if _, ok := bus.handlers[commandType]; ok {
+		return eris.Errorf("handler is already registered: %s", zero.CommandName())
+	}
+
+	bus.handlers[commandType] = func(ctx context.Context, cmd ICommand) (interface{}, error) {
+		return handler(ctx, cmd.(C))
+	}
+	return nil
+}
+
+// Dispatch は コマンドを登録済みのハンドラで処理します。
+func (b *Bus) Dispatch(ctx context.Context, cmd ICommand) (interface{}, error) {
+	handler, ok := b.handlers[reflect.TypeOf(cmd)]
+	if !ok {
+		return nil, eris.Errorf("handler is not registered: %s", cmd.CommandName())
+	}
+
+	for i := len(b.middlewares) - 1; i >= 0; i-- {
+		handler = b.middlewares[i](handler)
+	}
+
+	return handler(ctx, cmd)
+}
+
+// Dispatch は IBus.Dispatch の結果を型付きで受け取るためのヘルパーです。
+func Dispatch[R any](ctx context.Context, bus IBus, cmd ICommand) (R, error) {
+	var zero R
+	result, err := bus.Dispatch(ctx, cmd)
+	if err != nil {
+		return zero, err
+	}
+	if result == nil {
+		return zero, nil
+	}
+
+	typed, ok := result.(R)
+	if !ok {
+		return zero, eris.Errorf("unexpected result type of %s: %T", cmd.CommandName(), result)
+	}
+	return typed, nil
+}
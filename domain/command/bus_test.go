@@ -0,0 +1,198 @@
+package command_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/command"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+	infraSystem "github.com/t-kuni/cqrs-example/infrastructure/system"
+	customValidator "github.com/t-kuni/cqrs-example/validator"
+	"go.uber.org/mock/gomock"
+
+	systemInterface "github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+type greet struct {
+	Name string `validate:"required"`
+}
+
+func (greet) CommandName() string { return "Greet" }
+
+type greetTwice struct {
+	Name string
+}
+
+func (greetTwice) CommandName() string { return "GreetTwice" }
+
+type greeted struct {
+	Name string
+}
+
+func (greeted) EventName() string { return "Greeted" }
+
+// fakeConnector は 最も外側の Transaction の終了時にコミットしたことを記録する db.IConnector です
+// トランザクション内のコンテキストで呼び出した場合は外側のトランザクションに参加します
+type fakeConnector struct {
+	committed bool
+}
+
+func (c *fakeConnector) GetDB() *sql.DB             { return nil }
+func (c *fakeConnector) GetEnt() *ent.Client        { return nil }
+func (c *fakeConnector) GetReplicaEnt() *ent.Client { return nil }
+
+func (c *fakeConnector) Transaction(ctx context.Context, fn func(ctx context.Context, tx *ent.Client) error) error {
+	if tx, ok := db.TxClient(ctx); ok {
+		return fn(ctx, tx)
+	}
+	tx := &ent.Client{}
+	if err := fn(db.WithTxClient(ctx, tx), tx); err != nil {
+		return err
+	}
+	c.committed = true
+	return nil
+}
+
+type recordingStore struct {
+	appended []event.Event
+}
+
+func (s *recordingStore) Append(ctx context.Context, client *ent.Client, events []event.Event) error {
+	s.appended = append(s.appended, events...)
+	return nil
+}
+
+// recordingDispatcher は 配信されたイベントと配信時にコミット済みだったかを記録する event.IDispatcher です
+type recordingDispatcher struct {
+	conn       *fakeConnector
+	dispatched [][]event.Event
+	committed  []bool
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, events []event.Event) error {
+	d.dispatched = append(d.dispatched, events)
+	d.committed = append(d.committed, d.conn.committed)
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	t.Run("登録したハンドラでコマンドを処理し、ミドルウェアが外側から順に実行されること", func(t *testing.T) {
+		var calls []string
+		trace := func(name string) command.Middleware {
+			return func(next command.HandlerFunc) command.HandlerFunc {
+				return func(ctx context.Context, cmd command.ICommand) (interface{}, error) {
+					calls = append(calls, name+":before")
+					result, err := next(ctx, cmd)
+					calls = append(calls, name+":after")
+					return result, err
+				}
+			}
+		}
+
+		bus := command.NewBus(trace("outer"), trace("inner"))
+		err := command.Register(bus, func(ctx context.Context, cmd greet) (string, error) {
+			calls = append(calls, "handler")
+			return "Hello, " + cmd.Name, nil
+		})
+		assert.NoError(t, err)
+
+		result, err := command.Dispatch[string](context.Background(), bus, greet{Name: "テスト"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello, テスト", result)
+		assert.Equal(t, []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}, calls)
+	})
+
+	t.Run("同じコマンドのハンドラを二重に登録できないこと", func(t *testing.T) {
+		bus := command.NewBus()
+		handler := func(ctx context.Context, cmd greet) (string, error) { return "", nil }
+
+		assert.NoError(t, command.Register(bus, handler))
+		assert.Error(t, command.Register(bus, handler))
+	})
+
+	t.Run("ハンドラが登録されていないコマンドはエラーになること", func(t *testing.T) {
+		bus := command.NewBus()
+
+		_, err := bus.Dispatch(context.Background(), greet{Name: "テスト"})
+
+		assert.ErrorContains(t, err, "handler is not registered: Greet")
+	})
+
+	t.Run("バリデーションエラーの場合ハンドラが実行されないこと", func(t *testing.T) {
+		v, err := customValidator.NewCustomValidator()
+		assert.NoError(t, err)
+
+		called := false
+		bus := command.NewBus(command.ValidationMiddleware(v))
+		err = command.Register(bus, func(ctx context.Context, cmd greet) (string, error) {
+			called = true
+			return "", nil
+		})
+		assert.NoError(t, err)
+
+		_, err = bus.Dispatch(context.Background(), greet{})
+
+		var vErr validator.ValidationErrors
+		assert.ErrorAs(t, err, &vErr)
+		assert.Equal(t, "required", vErr[0].Tag())
+		assert.False(t, called)
+	})
+	t.Run("他のコマンドのトランザクション内で実行したコマンドのイベントは、外側のコミット後にまとめて配信されること", func(t *testing.T) {
+		conn := &fakeConnector{}
+		store := &recordingStore{}
+		dispatcher := &recordingDispatcher{conn: conn}
+		logger := systemInterface.NewMockILogger(gomock.NewController(t))
+
+		bus := command.NewBus(
+			command.EventMiddleware(dispatcher, logger),
+			command.TransactionMiddleware(conn, store),
+		)
+		err := command.Register(bus, func(ctx context.Context, cmd greet) (string, error) {
+			command.RecordEvent(ctx, greeted{Name: cmd.Name})
+			return cmd.Name, nil
+		})
+		assert.NoError(t, err)
+		err = command.Register(bus, func(ctx context.Context, cmd greetTwice) (string, error) {
+			if _, err := command.Dispatch[string](ctx, bus, greet{Name: "内側"}); err != nil {
+				return "", err
+			}
+			command.RecordEvent(ctx, greeted{Name: cmd.Name})
+			return cmd.Name, nil
+		})
+		assert.NoError(t, err)
+
+		_, err = command.Dispatch[string](context.Background(), bus, greetTwice{Name: "外側"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []event.Event{greeted{Name: "内側"}, greeted{Name: "外側"}}, store.appended)
+		assert.Equal(t, [][]event.Event{{greeted{Name: "内側"}, greeted{Name: "外側"}}}, dispatcher.dispatched)
+		assert.Equal(t, []bool{true}, dispatcher.committed)
+	})
+	t.Run("コマンドのログにコンテキストのリクエストIDとトレースIDが出力されること", func(t *testing.T) {
+		logger, hook := infraSystem.NewTestLogger()
+		bus := command.NewBus(command.LoggingMiddleware(logger))
+		err := command.Register(bus, func(ctx context.Context, cmd greet) (string, error) {
+			return cmd.Name, nil
+		})
+		assert.NoError(t, err)
+
+		traceContext := correlation.NewTraceContext(nil)
+		ctx := correlation.WithTraceContext(correlation.WithRequestID(context.Background(), "req-1"), traceContext)
+		_, err = command.Dispatch[string](ctx, bus, greet{Name: "テスト"})
+
+		assert.NoError(t, err)
+		entry := hook.LastEntry()
+		if assert.NotNil(t, entry) {
+			assert.Equal(t, "[Command]Greet", entry.Message)
+			assert.Equal(t, "req-1", entry.Data["request_id"])
+			assert.Equal(t, traceContext.TraceID, entry.Data["trace_id"])
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/ent"
+)
+
+// RenameCategory は categoryの名前を変更するコマンドです。
+type RenameCategory struct {
+	ID   uuid.UUID `validate:"required"`
+	Name string    `validate:"required,max=255"`
+}
+
+func (RenameCategory) CommandName() string { return "RenameCategory" }
+
+// CategoryCommandHandler は categoryに関するコマンドを処理します。
+type CategoryCommandHandler struct{}
+
+// NewCategoryCommandHandler は CategoryCommandHandler の新しいインスタンスを作成します。
+func NewCategoryCommandHandler() *CategoryCommandHandler {
+	return &CategoryCommandHandler{}
+}
+
+// Rename は RenameCategory コマンドを処理します。
+// category が存在しない場合は ent.NotFoundError を返します。
+// 名前が変わらない場合はイベントを記録しません。
+func (h CategoryCommandHandler) Rename(ctx context.Context, cmd RenameCategory) (*ent.Category, error) {
+	client := EntClient(ctx)
+
+	current, err := client.Category.Get(ctx, cmd.ID)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	updated, err := current.Update().
+		SetName(cmd.Name).
+		Save(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	if current.Name != updated.Name {
+		RecordEvent(ctx, event.CategoryRenamed{
+			CategoryID: updated.ID,
+			OldName:    current.Name,
+			NewName:    updated.Name,
+		})
+	}
+	return updated, nil
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+)
+
+type recordedEventsKey struct{}
+
+// recordedEvents はコマンドの処理中に記録されたドメインイベントを保持します
+type recordedEvents struct {
+	events []event.Event
+	// nested は 内側で実行されたコマンドが記録し、永続化済みのドメインイベントです
+	nested []event.Event
+}
+
+// dispatchable は 配信するドメインイベントを永続化した順に返します
+// 内側のコマンドのイベントは外側のコマンドのイベントより先に永続化されます
+func (r *recordedEvents) dispatchable() []event.Event {
+	return append(append([]event.Event{}, r.nested...), r.events...)
+}
+
+// EntClient は コマンドを処理しているトランザクションのentクライアントを返します。
+// コマンドハンドラ内のDBアクセスはこのクライアントを使用してください。
+func EntClient(ctx context.Context) *ent.Client {
+	client, _ := db.TxClient(ctx)
+	return client
+}
+
+// RecordEvent は コミット後に発行するドメインイベントを記録します。
+// コマンドがエラーになった場合、記録したイベントは破棄されます。
+func RecordEvent(ctx context.Context, events ...event.Event) {
+	recorded := recordedEventsFrom(ctx)
+	if recorded == nil {
+		return
+	}
+	recorded.events = append(recorded.events, events...)
+}
+
+func withRecordedEvents(ctx context.Context) (context.Context, *recordedEvents) {
+	recorded := &recordedEvents{}
+	return context.WithValue(ctx, recordedEventsKey{}, recorded), recorded
+}
+
+// resetRecordedEvents は 記録されたドメインイベントを破棄します
+// トランザクションをやり直す際に、失敗した試行で記録したイベントが重複しないようにします
+func resetRecordedEvents(ctx context.Context) {
+	recorded := recordedEventsFrom(ctx)
+	if recorded == nil {
+		return
+	}
+	recorded.events = nil
+	recorded.nested = nil
+}
+
+func recordedEventsOf(ctx context.Context) []event.Event {
+	recorded := recordedEventsFrom(ctx)
+	if recorded == nil {
+		return nil
+	}
+	return recorded.events
+}
+
+func recordedEventsFrom(ctx context.Context) *recordedEvents {
+	recorded, _ := ctx.Value(recordedEventsKey{}).(*recordedEvents)
+	return recorded
+}
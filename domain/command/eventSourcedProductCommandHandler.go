@@ -0,0 +1,191 @@
+package command
+
+import (
+	"context"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/aggregate"
+	"github.com/t-kuni/cqrs-example/domain/eventsourcing"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// EventSourcedProductCommandHandler は productに関するコマンドをイベントソーシングで処理します。
+// 状態の正はイベントストア（events テーブル）で、products テーブルは同じトランザクション内で射影として更新されます。
+type EventSourcedProductCommandHandler struct {
+	UuidGenerator system.IUuidGenerator
+	Timer         system.ITimer
+	Repository    eventsourcing.IProductRepository
+	Projector     eventsourcing.IProductProjector
+}
+
+// NewEventSourcedProductCommandHandler は EventSourcedProductCommandHandler の新しいインスタンスを作成します。
+func NewEventSourcedProductCommandHandler(
+	uuidGenerator system.IUuidGenerator,
+	timer system.ITimer,
+	repository eventsourcing.IProductRepository,
+	projector eventsourcing.IProductProjector,
+) *EventSourcedProductCommandHandler {
+	return &EventSourcedProductCommandHandler{
+		UuidGenerator: uuidGenerator,
+		Timer:         timer,
+		Repository:    repository,
+		Projector:     projector,
+	}
+}
+
+// SelectProductCommandHandler は 設定の Sync.ProductWriteModel に応じて product のコマンドハンドラを選択します。
+// "event_sourcing" の場合はイベントソーシング、それ以外の場合は products テーブルを直接更新するハンドラを使用します。
+func SelectProductCommandHandler(cfg *config.Config, crud *ProductCommandHandler, eventSourced *EventSourcedProductCommandHandler) IProductCommandHandler {
+	if cfg.Sync.ProductWriteModel == config.ProductWriteModelEventSourcing {
+		return eventSourced
+	}
+	return crud
+}
+
+// Create は CreateProduct コマンドを処理します。
+// tenant または category が存在しない場合は BasicBusinessError を返します。
+func (h EventSourcedProductCommandHandler) Create(ctx context.Context, cmd CreateProduct) (*ent.Product, error) {
+	client := EntClient(ctx)
+
+	if err := ensureTenantExists(ctx, client, cmd.TenantID); err != nil {
+		return nil, err
+	}
+	if err := ensureCategoryExists(ctx, client, cmd.CategoryID); err != nil {
+		return nil, err
+	}
+
+	id, err := generateID(h.UuidGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	p := aggregate.NewProduct(id)
+	if err := p.Create(cmd.TenantID, cmd.CategoryID, cmd.Name, cmd.Price, cmd.Properties, h.Timer.Now()); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if err := h.save(ctx, client, p); err != nil {
+		return nil, err
+	}
+
+	return h.getProjected(ctx, client, p)
+}
+
+// Update は UpdateProduct コマンドを処理します。
+// product が存在しない場合は aggregate.ErrProductNotFound を、category が存在しない場合は BasicBusinessError を、
+// version が取得時から変わっている場合は types.ConflictError を返します。
+func (h EventSourcedProductCommandHandler) Update(ctx context.Context, cmd UpdateProduct) (*ent.Product, error) {
+	client := EntClient(ctx)
+
+	if err := ensureCategoryExists(ctx, client, cmd.CategoryID); err != nil {
+		return nil, err
+	}
+
+	// version は products テーブル（射影）の値で確認する
+	// イベントストアはテナントの範囲に制限されないため、参照できるかの確認も兼ねる
+	projected, err := client.Product.Get(ctx, cmd.ID)
+	if ent.IsNotFound(err) {
+		return nil, eris.Wrap(aggregate.ErrProductNotFound, "")
+	}
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if projected.Version != cmd.Version {
+		return nil, types.NewConflictError("product was modified by another request", projected.Version)
+	}
+
+	p, err := h.load(ctx, client, projected)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Update(cmd.CategoryID, cmd.Name, cmd.Price, cmd.Properties); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if err := h.save(ctx, client, p); err != nil {
+		return nil, err
+	}
+
+	return h.getProjected(ctx, client, p)
+}
+
+// Delete は DeleteProduct コマンドを処理します。
+// product が存在しない場合は aggregate.ErrProductNotFound を返します。
+func (h EventSourcedProductCommandHandler) Delete(ctx context.Context, cmd DeleteProduct) (struct{}, error) {
+	client := EntClient(ctx)
+
+	// イベントストアはテナントの範囲に制限されないため、products テーブル（射影）で参照できるかを確認する
+	projected, err := client.Product.Get(ctx, cmd.ID)
+	if ent.IsNotFound(err) {
+		return struct{}{}, eris.Wrap(aggregate.ErrProductNotFound, "")
+	}
+	if err != nil {
+		return struct{}{}, eris.Wrap(err, "")
+	}
+
+	p, err := h.load(ctx, client, projected)
+	if err != nil {
+		return struct{}{}, err
+	}
+	if err := p.Delete(); err != nil {
+		return struct{}{}, eris.Wrap(err, "")
+	}
+	if err := h.save(ctx, client, p); err != nil {
+		return struct{}{}, err
+	}
+
+	return struct{}{}, nil
+}
+
+// load は products テーブル（射影）の行に対応する集約をイベントストアから読み込みます。
+// シードや CRUD のモデルで登録されイベントストリームを持たない行は、その時点の行の内容で集約として取り込みます。
+func (h EventSourcedProductCommandHandler) load(ctx context.Context, client *ent.Client, projected *ent.Product) (*aggregate.Product, error) {
+	p, err := h.Repository.Load(ctx, client, projected.ID)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if p.Version > 0 {
+		return p, nil
+	}
+
+	p, err = h.Repository.Adopt(ctx, client, projected)
+	if err != nil {
+		return nil, conflictOnConcurrency(err)
+	}
+	return p, nil
+}
+
+// save は 集約の新しいイベントをイベントストアに追記し、products テーブルに反映します。
+// 追記したイベントはドメインイベントとしても記録し、コミット後に OpenSearch へ反映されるようにします。
+// 読み込み後に他の処理が同じ集約を更新していた場合は types.ConflictError を返します。
+func (h EventSourcedProductCommandHandler) save(ctx context.Context, client *ent.Client, p *aggregate.Product) error {
+	changes := p.Changes()
+	afterVersion := p.Version - int64(len(changes))
+	if err := h.Repository.Save(ctx, client, p); err != nil {
+		return conflictOnConcurrency(err)
+	}
+	if err := h.Projector.Project(ctx, client, afterVersion, changes); err != nil {
+		return eris.Wrap(err, "")
+	}
+
+	RecordEvent(ctx, changes...)
+	return nil
+}
+
+// conflictOnConcurrency は イベントストアへの同時追記による eventsourcing.ConcurrencyError を types.ConflictError（409）に変換します。
+func conflictOnConcurrency(err error) error {
+	var concurrencyErr eventsourcing.ConcurrencyError
+	if eris.As(err, &concurrencyErr) {
+		return types.NewConflictError("product was modified by another request", concurrencyErr.ActualVersion)
+	}
+	return eris.Wrap(err, "")
+}
+
+func (h EventSourcedProductCommandHandler) getProjected(ctx context.Context, client *ent.Client, p *aggregate.Product) (*ent.Product, error) {
+	projected, err := client.Product.Get(ctx, p.ID)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return projected, nil
+}
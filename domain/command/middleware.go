@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// LoggingMiddleware はコマンドの実行結果と処理時間をログに出力します
+// コンテキストのリクエストIDとトレースコンテキストもログに出力します
+func LoggingMiddleware(logger system.ILogger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd ICommand) (interface{}, error) {
+			logger := logger.WithFields(correlation.Fields(ctx))
+			start := time.Now()
+			result, err := next(ctx, cmd)
+			latency := time.Since(start)
+
+			params := map[string]interface{}{
+				"command":       cmd.CommandName(),
+				"latency":       latency,
+				"latency_human": latency.String(),
+			}
+			if err != nil {
+				logger.WarnWithError(nil, err, params)
+				return nil, err
+			}
+			logger.Info(nil, "[Command]"+cmd.CommandName(), params)
+			return result, nil
+		}
+	}
+}
+
+// ValidationMiddleware はコマンドの構造体タグ（validate）に基づいてバリデーションを行います
+// バリデーションエラーは項目ごとのエラーを持つ types.ValidationError として返します
+func ValidationMiddleware(validator echo.Validator) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd ICommand) (interface{}, error) {
+			if err := validator.Validate(cmd); err != nil {
+				return nil, eris.Wrap(types.NewValidationErrorFromValidator(err), "")
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// EventMiddleware はコマンドの処理中に記録されたドメインイベントを処理の成功後にプロセス内の購読者へ配信します
+// TransactionMiddleware より外側に配置することで、最も外側のトランザクションのコミット後に配信されるようにします
+// 他のコマンドのトランザクション内で実行されたコマンドは配信せず、記録されたイベントを外側のコマンドに引き渡します
+// 外側のトランザクションがロールバックされた場合、引き渡したイベントは配信されません
+func EventMiddleware(dispatcher event.IDispatcher, logger system.ILogger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd ICommand) (interface{}, error) {
+			_, nested := db.TxClient(ctx)
+			outer := recordedEventsFrom(ctx)
+
+			ctx, recorded := withRecordedEvents(ctx)
+			result, err := next(ctx, cmd)
+			if err != nil {
+				return nil, err
+			}
+			if nested {
+				// 内側のイベントは SAVEPOINT 内で永続化済みのため、外側では配信のみ行う
+				if outer != nil {
+					outer.nested = append(outer.nested, recorded.dispatchable()...)
+				}
+				return result, nil
+			}
+
+			events := recorded.dispatchable()
+			if len(events) == 0 {
+				return result, nil
+			}
+
+			// コミット済みのため、配信に失敗してもコマンド自体は成功として扱う
+			// イベントは domain_events に永続化済みのため、永続購読者には別途配信される
+			if err := dispatcher.Dispatch(ctx, events); err != nil {
+				logger.WithFields(correlation.Fields(ctx)).Error(nil, eris.Wrap(err, ""), map[string]interface{}{
+					"command": cmd.CommandName(),
+				})
+			}
+			return result, nil
+		}
+	}
+}
+
+// TransactionMiddleware はコマンドの処理を1つのトランザクション内で実行します
+// コマンドハンドラは EntClient でトランザクションのクライアントを取得できます
+// 記録されたドメインイベントは同じトランザクション内で永続化します
+// デッドロックなどでトランザクションをやり直す場合は、コマンドハンドラも最初から実行し直します
+func TransactionMiddleware(conn db.IConnector, store event.IStore) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd ICommand) (interface{}, error) {
+			var result interface{}
+			err := conn.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+				resetRecordedEvents(ctx)
+
+				var err error
+				result, err = next(ctx, cmd)
+				if err != nil {
+					return err
+				}
+				return store.Append(ctx, tx, recordedEventsOf(ctx))
+			})
+			if err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+	}
+}
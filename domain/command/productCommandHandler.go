@@ -0,0 +1,204 @@
+package command
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/category"
+	"github.com/t-kuni/cqrs-example/ent/tenant"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// CreateProduct は productを登録するコマンドです。
+type CreateProduct struct {
+	TenantID   uuid.UUID `validate:"required"`
+	CategoryID uuid.UUID `validate:"required"`
+	Name       string    `validate:"required,max=255"`
+	Price      int64     `validate:"gte=0"`
+	Properties model.ProductProperties
+}
+
+func (CreateProduct) CommandName() string { return "CreateProduct" }
+
+// UpdateProduct は productを更新するコマンドです。
+// Version には取得時の version を指定します。更新済みの場合は types.ConflictError になります。
+type UpdateProduct struct {
+	ID         uuid.UUID `validate:"required"`
+	Version    int64     `validate:"gte=1"`
+	CategoryID uuid.UUID `validate:"required"`
+	Name       string    `validate:"required,max=255"`
+	Price      int64     `validate:"gte=0"`
+	Properties model.ProductProperties
+}
+
+func (UpdateProduct) CommandName() string { return "UpdateProduct" }
+
+// DeleteProduct は productを削除するコマンドです。
+type DeleteProduct struct {
+	ID uuid.UUID `validate:"required"`
+}
+
+func (DeleteProduct) CommandName() string { return "DeleteProduct" }
+
+// IProductCommandHandler は productに関するコマンドを処理するハンドラのインターフェースです。
+// RDBの products テーブルを直接更新する ProductCommandHandler と、
+// イベントソーシングで状態を管理する EventSourcedProductCommandHandler があります。
+type IProductCommandHandler interface {
+	Create(ctx context.Context, cmd CreateProduct) (*ent.Product, error)
+	Update(ctx context.Context, cmd UpdateProduct) (*ent.Product, error)
+	Delete(ctx context.Context, cmd DeleteProduct) (struct{}, error)
+}
+
+// ProductCommandHandler は productに関するコマンドを処理します。
+type ProductCommandHandler struct {
+	UuidGenerator system.IUuidGenerator
+	Timer         system.ITimer
+}
+
+// NewProductCommandHandler は ProductCommandHandler の新しいインスタンスを作成します。
+func NewProductCommandHandler(uuidGenerator system.IUuidGenerator, timer system.ITimer) *ProductCommandHandler {
+	return &ProductCommandHandler{
+		UuidGenerator: uuidGenerator,
+		Timer:         timer,
+	}
+}
+
+// Create は CreateProduct コマンドを処理します。
+// tenant または category が存在しない場合は BasicBusinessError を返します。
+func (h ProductCommandHandler) Create(ctx context.Context, cmd CreateProduct) (*ent.Product, error) {
+	client := EntClient(ctx)
+
+	if err := ensureTenantExists(ctx, client, cmd.TenantID); err != nil {
+		return nil, err
+	}
+	if err := ensureCategoryExists(ctx, client, cmd.CategoryID); err != nil {
+		return nil, err
+	}
+
+	id, err := generateID(h.UuidGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := client.Product.Create().
+		SetID(id).
+		SetTenantID(cmd.TenantID).
+		SetCategoryID(cmd.CategoryID).
+		SetName(cmd.Name).
+		SetPrice(cmd.Price).
+		SetProperties(&cmd.Properties).
+		SetListedAt(h.Timer.Now()).
+		Save(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	RecordEvent(ctx, event.ProductCreated{
+		ProductID:  created.ID,
+		TenantID:   created.TenantID,
+		CategoryID: created.CategoryID,
+		Name:       created.Name,
+		Price:      created.Price,
+		Properties: *created.Properties,
+		ListedAt:   created.ListedAt,
+	})
+	return created, nil
+}
+
+// Update は UpdateProduct コマンドを処理します。
+// product が存在しない場合は ent.NotFoundError を、category が存在しない場合は BasicBusinessError を、
+// version が取得時から変わっている場合は types.ConflictError を返します。
+func (h ProductCommandHandler) Update(ctx context.Context, cmd UpdateProduct) (*ent.Product, error) {
+	client := EntClient(ctx)
+
+	if err := ensureCategoryExists(ctx, client, cmd.CategoryID); err != nil {
+		return nil, err
+	}
+
+	current, err := client.Product.Get(ctx, cmd.ID)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	updated, err := current.Update().
+		SetVersion(cmd.Version).
+		SetCategoryID(cmd.CategoryID).
+		SetName(cmd.Name).
+		SetPrice(cmd.Price).
+		SetProperties(&cmd.Properties).
+		Save(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	RecordEvent(ctx, event.ProductUpdated{
+		ProductID:  updated.ID,
+		CategoryID: updated.CategoryID,
+		Name:       updated.Name,
+		Properties: *updated.Properties,
+	})
+	if current.Price != updated.Price {
+		RecordEvent(ctx, event.ProductPriceChanged{
+			ProductID: updated.ID,
+			OldPrice:  current.Price,
+			NewPrice:  updated.Price,
+		})
+	}
+	return updated, nil
+}
+
+// Delete は DeleteProduct コマンドを処理します。
+// product が存在しない場合は ent.NotFoundError を返します。
+func (h ProductCommandHandler) Delete(ctx context.Context, cmd DeleteProduct) (struct{}, error) {
+	client := EntClient(ctx)
+
+	if err := client.Product.DeleteOneID(cmd.ID).Exec(ctx); err != nil {
+		return struct{}{}, eris.Wrap(err, "")
+	}
+
+	RecordEvent(ctx, event.ProductDeleted{ProductID: cmd.ID})
+	return struct{}{}, nil
+}
+
+func ensureTenantExists(ctx context.Context, client *ent.Client, tenantID uuid.UUID) error {
+	exists, err := client.Tenant.Query().Where(tenant.ID(tenantID)).Exist(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if !exists {
+		return types.NewBasicBusinessError("tenant not found", map[string]interface{}{
+			"tenantId": tenantID.String(),
+		})
+	}
+	return nil
+}
+
+func ensureCategoryExists(ctx context.Context, client *ent.Client, categoryID uuid.UUID) error {
+	exists, err := client.Category.Query().Where(category.ID(categoryID)).Exist(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if !exists {
+		return types.NewBasicBusinessError("category not found", map[string]interface{}{
+			"categoryId": categoryID.String(),
+		})
+	}
+	return nil
+}
+
+func generateID(uuidGenerator system.IUuidGenerator) (uuid.UUID, error) {
+	idStr, err := uuidGenerator.Generate()
+	if err != nil {
+		return uuid.Nil, eris.Wrap(err, "")
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, eris.Wrap(err, "")
+	}
+	return id, nil
+}
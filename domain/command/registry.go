@@ -0,0 +1,43 @@
+package command
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// NewCommandBus は ミドルウェアとコマンドハンドラを登録済みの IBus を作成します。
+// ミドルウェアは ログ出力 → バリデーション → イベント配信 → トランザクション の順に実行されます。
+func NewCommandBus(
+	validator echo.Validator,
+	conn db.IConnector,
+	logger system.ILogger,
+	dispatcher event.IDispatcher,
+	store event.IStore,
+	productHandler IProductCommandHandler,
+	categoryHandler *CategoryCommandHandler,
+) (IBus, error) {
+	bus := NewBus(
+		LoggingMiddleware(logger),
+		ValidationMiddleware(validator),
+		EventMiddleware(dispatcher, logger),
+		TransactionMiddleware(conn, store),
+	)
+
+	if err := Register(bus, productHandler.Create); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if err := Register(bus, productHandler.Update); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if err := Register(bus, productHandler.Delete); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if err := Register(bus, categoryHandler.Rename); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	return bus, nil
+}
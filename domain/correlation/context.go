@@ -0,0 +1,132 @@
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// TraceContext は W3C Trace Context の traceparent の値です
+// https://www.w3.org/TR/trace-context/#traceparent-header
+type TraceContext struct {
+	// TraceID は 分散トレース全体で共通の 32 桁の16進数です
+	TraceID string
+	// SpanID は このサーバでの処理を表す 16 桁の16進数です（下流には parent-id として伝播します）
+	SpanID string
+	// ParentSpanID は 上流から受け取った parent-id です（上流が無い場合は空文字）
+	ParentSpanID string
+	// Flags は trace-flags の 2 桁の16進数です
+	Flags string
+}
+
+var traceparentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// requestIDPattern は 受け付ける X-Request-ID の形式です（ログやヘッダへの注入を防ぐため英数字と一部の記号に限定します）
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// ParseTraceparent は traceparent ヘッダの値を解析します
+// 形式が不正な場合や trace-id・parent-id が全て0の場合は ok が false になります
+func ParseTraceparent(value string) (parent TraceContext, ok bool) {
+	m := traceparentPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || m[1] == "ff" {
+		return TraceContext{}, false
+	}
+	if m[2] == strings.Repeat("0", 32) || m[3] == strings.Repeat("0", 16) {
+		return TraceContext{}, false
+	}
+	return TraceContext{
+		TraceID: m[2],
+		SpanID:  m[3],
+		Flags:   m[4],
+	}, true
+}
+
+// NewTraceContext は 上流の traceparent を引き継いで（無い場合は新しいトレースを開始して）このサーバのスパンを生成します
+func NewTraceContext(parent *TraceContext) TraceContext {
+	if parent == nil {
+		return TraceContext{
+			TraceID: randomHex(16),
+			SpanID:  randomHex(8),
+			Flags:   "01",
+		}
+	}
+	return TraceContext{
+		TraceID:      parent.TraceID,
+		SpanID:       randomHex(8),
+		ParentSpanID: parent.SpanID,
+		Flags:        parent.Flags,
+	}
+}
+
+// Traceparent は 下流に伝播する traceparent ヘッダの値を返します
+func (t TraceContext) Traceparent() string {
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + t.Flags
+}
+
+// IsValidRequestID は 外部から受け取った X-Request-ID をそのまま使用できるかどうかを返します
+func IsValidRequestID(id string) bool {
+	return requestIDPattern.MatchString(id)
+}
+
+type requestIDKey struct{}
+type traceContextKey struct{}
+type runIDKey struct{}
+
+// WithRequestID は リクエストIDを設定したコンテキストを返します
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom は コンテキストのリクエストIDを返します（設定されていない場合は空文字）
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTraceContext は トレースコンテキストを設定したコンテキストを返します
+func WithTraceContext(ctx context.Context, traceContext TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext)
+}
+
+// TraceContextFrom は コンテキストのトレースコンテキストを返します
+func TraceContextFrom(ctx context.Context) (TraceContext, bool) {
+	t, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return t, ok
+}
+
+// WithRunID は バッチ処理の実行IDを設定したコンテキストを返します
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// RunIDFrom は コンテキストのバッチ処理の実行IDを返します（設定されていない場合は空文字）
+func RunIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(runIDKey{}).(string)
+	return id
+}
+
+// Fields は コンテキストの相関IDをログに出力するフィールドとして返します
+func Fields(ctx context.Context) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if id := RequestIDFrom(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if t, ok := TraceContextFrom(ctx); ok {
+		fields["trace_id"] = t.TraceID
+		fields["span_id"] = t.SpanID
+	}
+	if id := RunIDFrom(ctx); id != "" {
+		fields["run_id"] = id
+	}
+	return fields
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,67 @@
+package correlation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		ok    bool
+	}{
+		{"正しい形式", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"空文字", "", false},
+		{"大文字を含む", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"versionがff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"trace-idが全て0", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"parent-idが全て0", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, ok := correlation.ParseTraceparent(c.value)
+			assert.Equal(t, c.ok, ok)
+		})
+	}
+}
+
+func TestNewTraceContext(t *testing.T) {
+	t.Run("上流が無い場合は新しいトレースを開始すること", func(t *testing.T) {
+		tc := correlation.NewTraceContext(nil)
+
+		_, ok := correlation.ParseTraceparent(tc.Traceparent())
+		assert.True(t, ok)
+		assert.Empty(t, tc.ParentSpanID)
+	})
+
+	t.Run("上流のトレースを引き継ぐこと", func(t *testing.T) {
+		parent, _ := correlation.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		tc := correlation.NewTraceContext(&parent)
+
+		assert.Equal(t, parent.TraceID, tc.TraceID)
+		assert.Equal(t, parent.SpanID, tc.ParentSpanID)
+		assert.NotEqual(t, parent.SpanID, tc.SpanID)
+		assert.Equal(t, "00", tc.Flags)
+	})
+}
+
+func TestFields(t *testing.T) {
+	assert.Empty(t, correlation.Fields(context.Background()))
+
+	ctx := correlation.WithRequestID(context.Background(), "req-1")
+	ctx = correlation.WithRunID(ctx, "run-1")
+	tc := correlation.NewTraceContext(nil)
+	ctx = correlation.WithTraceContext(ctx, tc)
+
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req-1",
+		"trace_id":   tc.TraceID,
+		"span_id":    tc.SpanID,
+		"run_id":     "run-1",
+	}, correlation.Fields(ctx))
+}
@@ -0,0 +1,54 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package event
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rotisserie/eris"
+)
+
+// ISubscriber は ドメインイベントの購読者のインターフェースです。
+// 射影の更新、監査ログ、通知など、書き込み処理とは独立した後続処理を実装する際に使用します。
+type ISubscriber interface {
+	// SubscriberName は 購読者を識別する名前を返します。
+	// 永続購読では処理済みの位置を記録するキーとして使用するため、変更しないでください。
+	SubscriberName() string
+
+	// Handle は ドメインイベントを処理します。
+	// 購読対象外のイベントは何もせずに nil を返してください。
+	Handle(ctx context.Context, e Event) error
+}
+
+// IDispatcher は ドメインイベントをプロセス内の購読者に配信するインターフェースです。
+// コマンドのトランザクションがコミットされた後に呼び出されることを想定しています。
+type IDispatcher interface {
+	// Dispatch は ドメインイベントを全ての購読者に同期的に配信します。
+	// 一部の購読者でエラーが発生しても残りの購読者には配信し、発生したエラーをまとめて返します。
+	Dispatch(ctx context.Context, events []Event) error
+}
+
+// Dispatcher は IDispatcher の実装です。
+type Dispatcher struct {
+	subscribers []ISubscriber
+}
+
+// NewDispatcher は Dispatcher の新しいインスタンスを作成します。
+func NewDispatcher(subscribers []ISubscriber) (IDispatcher, error) {
+	return &Dispatcher{
+		subscribers: subscribers,
+	}, nil
+}
+
+// Dispatch は ドメインイベントを全ての購読者に同期的に配信します。
+func (d *Dispatcher) Dispatch(ctx context.Context, events []Event) error {
+	var errs []error
+	for _, e := range events {
+		for _, s := range d.subscribers {
+			if err := s.Handle(ctx, e); err != nil {
+				errs = append(errs, eris.Wrapf(err, "subscriber %s failed to handle %s", s.SubscriberName(), e.EventName()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
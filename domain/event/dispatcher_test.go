@@ -0,0 +1,74 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSubscriber struct {
+	name    string
+	err     error
+	handled []Event
+}
+
+func (s *recordingSubscriber) SubscriberName() string { return s.name }
+
+func (s *recordingSubscriber) Handle(ctx context.Context, e Event) error {
+	s.handled = append(s.handled, e)
+	return s.err
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("全ての購読者に全てのイベントが配信されること", func(t *testing.T) {
+		first := &recordingSubscriber{name: "first"}
+		second := &recordingSubscriber{name: "second"}
+		dispatcher, err := NewDispatcher([]ISubscriber{first, second})
+		assert.NoError(t, err)
+
+		events := []Event{
+			ProductDeleted{ProductID: uuid.New()},
+			CategoryRenamed{CategoryID: uuid.New(), OldName: "old", NewName: "new"},
+		}
+		err = dispatcher.Dispatch(context.Background(), events)
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, first.handled)
+		assert.Equal(t, events, second.handled)
+	})
+
+	t.Run("一部の購読者がエラーになっても残りの購読者に配信されること", func(t *testing.T) {
+		failing := &recordingSubscriber{name: "failing", err: errors.New("boom")}
+		succeeding := &recordingSubscriber{name: "succeeding"}
+		dispatcher, err := NewDispatcher([]ISubscriber{failing, succeeding})
+		assert.NoError(t, err)
+
+		events := []Event{ProductDeleted{ProductID: uuid.New()}}
+		err = dispatcher.Dispatch(context.Background(), events)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failing")
+		assert.Equal(t, events, succeeding.handled)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("永続化したイベントを元の型に復元できること", func(t *testing.T) {
+		original := ProductPriceChanged{ProductID: uuid.New(), OldPrice: 100, NewPrice: 200}
+		payload := []byte(`{"product_id":"` + original.ProductID.String() + `","old_price":100,"new_price":200}`)
+
+		decoded, err := Decode(original.EventName(), payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, original, decoded)
+	})
+
+	t.Run("未知のイベント名の場合はエラーになること", func(t *testing.T) {
+		_, err := Decode("Unknown", []byte(`{}`))
+
+		assert.Error(t, err)
+	})
+}
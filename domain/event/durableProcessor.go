@@ -0,0 +1,136 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/domainevent"
+	"github.com/t-kuni/cqrs-example/ent/eventsubscriberoffset"
+)
+
+// EventGapTimeout は ID が連続していないイベントを、前の ID のイベントのコミットを待たずに配信するまでの時間です。
+// ID は INSERT 時に採番されコミット時に見えるようになるため、後に採番されたイベントが先に見えることがあります。
+// この時間を過ぎても埋まらない ID はロールバックされたものとして読み飛ばします。
+const EventGapTimeout = time.Minute
+
+// IDurableProcessor は domain_events テーブルに永続化されたイベントを購読者に配信するインターフェースです。
+// 購読者ごとに処理済みの位置を記録するため、購読者は書き込み処理やほかの購読者と独立して処理を進められます。
+type IDurableProcessor interface {
+	// Process は 購読者が未処理のイベントを古い順に最大 batchSize 件処理し、処理した件数を返します。
+	// ID が連続していないイベントは、前の ID のイベントがコミットされるか EventGapTimeout を過ぎるまで処理しません。
+	// イベントを1件処理するごとに処理済みの位置を記録するため、途中でエラーになった場合は次回そのイベントから再開します（at-least-once）。
+	Process(ctx context.Context, subscriber ISubscriber, batchSize int) (int, error)
+
+	// Lag は 購読者が未処理のイベントの件数を返します。
+	Lag(ctx context.Context, subscriberName string) (int, error)
+}
+
+// DurableProcessor は IDurableProcessor の実装です。
+type DurableProcessor struct {
+	DBConnector db.IConnector
+	Timer       system.ITimer
+}
+
+// NewDurableProcessor は DurableProcessor の新しいインスタンスを作成します。
+func NewDurableProcessor(conn db.IConnector, timer system.ITimer) (IDurableProcessor, error) {
+	return &DurableProcessor{
+		DBConnector: conn,
+		Timer:       timer,
+	}, nil
+}
+
+// Process は 購読者が未処理のイベントを古い順に最大 batchSize 件処理し、処理した件数を返します。
+func (p *DurableProcessor) Process(ctx context.Context, subscriber ISubscriber, batchSize int) (int, error) {
+	client := p.DBConnector.GetEnt()
+
+	lastEventID, err := p.getLastEventID(ctx, client, subscriber.SubscriberName())
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+
+	rows, err := client.DomainEvent.Query().
+		Where(domainevent.IDGT(lastEventID)).
+		Order(ent.Asc(domainevent.FieldID)).
+		Limit(batchSize).
+		All(ctx)
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+
+	for i, row := range rows {
+		// 前の ID のイベントがまだコミットされていない可能性があるため、EventGapTimeout を過ぎるまでは ID が連続するところまでで止める
+		// 先に配信して位置を進めると、後からコミットされた前の ID のイベントを配信できなくなる
+		if row.ID != lastEventID+1 && p.Timer.Now().Sub(row.OccurredAt) < EventGapTimeout {
+			return i, nil
+		}
+
+		e, err := Decode(row.Name, row.Payload)
+		if err != nil {
+			return i, eris.Wrap(err, "")
+		}
+		if err := subscriber.Handle(ctx, e); err != nil {
+			return i, eris.Wrapf(err, "subscriber %s failed to handle event %d", subscriber.SubscriberName(), row.ID)
+		}
+		if err := p.saveLastEventID(ctx, client, subscriber.SubscriberName(), row.ID); err != nil {
+			return i, eris.Wrap(err, "")
+		}
+		lastEventID = row.ID
+	}
+
+	return len(rows), nil
+}
+
+// Lag は 購読者が未処理のイベントの件数を返します。
+func (p *DurableProcessor) Lag(ctx context.Context, subscriberName string) (int, error) {
+	client := p.DBConnector.GetEnt()
+
+	lastEventID, err := p.getLastEventID(ctx, client, subscriberName)
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+
+	lag, err := client.DomainEvent.Query().
+		Where(domainevent.IDGT(lastEventID)).
+		Count(ctx)
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+	return lag, nil
+}
+
+func (p *DurableProcessor) getLastEventID(ctx context.Context, client *ent.Client, subscriberName string) (int64, error) {
+	offset, err := client.EventSubscriberOffset.Get(ctx, subscriberName)
+	if ent.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+	return offset.LastEventID, nil
+}
+
+func (p *DurableProcessor) saveLastEventID(ctx context.Context, client *ent.Client, subscriberName string, eventID int64) error {
+	now := p.Timer.Now()
+	updated, err := client.EventSubscriberOffset.Update().
+		Where(eventsubscriberoffset.ID(subscriberName)).
+		SetLastEventID(eventID).
+		SetUpdatedAt(now).
+		Save(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	return client.EventSubscriberOffset.Create().
+		SetID(subscriberName).
+		SetLastEventID(eventID).
+		SetUpdatedAt(now).
+		Exec(ctx)
+}
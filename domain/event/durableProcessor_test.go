@@ -0,0 +1,97 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/testUtil"
+)
+
+type recordingSubscriber struct {
+	handled []event.Event
+}
+
+func (s *recordingSubscriber) SubscriberName() string { return "test" }
+
+func (s *recordingSubscriber) Handle(ctx context.Context, e event.Event) error {
+	s.handled = append(s.handled, e)
+	return nil
+}
+
+func TestDurableProcessor_Process(t *testing.T) {
+	first := event.ProductDeleted{ProductID: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	second := event.ProductDeleted{ProductID: uuid.MustParse("00000000-0000-0000-0000-000000000002")}
+
+	appendEvent := func(client *ent.Client, id int64, e event.Event, occurredAt string) {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			panic(err)
+		}
+		client.DomainEvent.Create().
+			SetID(id).
+			SetName(e.EventName()).
+			SetPayload(payload).
+			SetOccurredAt(testUtil.MustNewDateTime(occurredAt)).
+			ExecX(context.Background())
+	}
+
+	t.Run("後から採番されたイベントが先にコミットされた場合も、両方のイベントを採番順に配信すること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee event.IDurableProcessor
+		var client *ent.Client
+		cont.Exec(func(p event.IDurableProcessor, conn db.IConnector) {
+			testee = p
+			client = conn.GetEnt()
+		})
+
+		ctx := context.Background()
+		subscriber := &recordingSubscriber{}
+
+		// ID 2 のトランザクションが ID 1 より先にコミットされた状態
+		appendEvent(client, 2, second, "2025-01-02T03:04:05Z")
+		processed, err := testee.Process(ctx, subscriber, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		// ID 1 のトランザクションがコミットされた
+		appendEvent(client, 1, first, "2025-01-02T03:04:04Z")
+		processed, err = testee.Process(ctx, subscriber, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, processed)
+		assert.Equal(t, []event.Event{first, second}, subscriber.handled)
+	})
+
+	t.Run("EventGapTimeout を過ぎても埋まらない ID は読み飛ばすこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee event.IDurableProcessor
+		var client *ent.Client
+		cont.Exec(func(p event.IDurableProcessor, conn db.IConnector) {
+			testee = p
+			client = conn.GetEnt()
+		})
+
+		ctx := context.Background()
+		subscriber := &recordingSubscriber{}
+
+		// ID 1 はロールバックされた
+		appendEvent(client, 2, second, "2025-01-02T03:03:05Z")
+		processed, err := testee.Process(ctx, subscriber, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, []event.Event{second}, subscriber.handled)
+	})
+}
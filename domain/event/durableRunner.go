@@ -0,0 +1,100 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// IDurableRunner は 永続購読者の処理をバックグラウンドで繰り返し実行するインターフェースです。
+type IDurableRunner interface {
+	// Start は 購読者の処理をバックグラウンドで開始します。
+	// 未処理のイベントが無い場合は interval だけ待ってから、最大 batchSize 件ずつ処理します。
+	Start(subscriber ISubscriber, interval time.Duration, batchSize int)
+
+	// Stop は 処理中のバッチの完了を待ってから停止します。
+	// ctx の期限までに完了しない場合は処理を中断してエラーを返します（中断したイベントは次回の起動時に再処理されます）。
+	Stop(ctx context.Context) error
+}
+
+// DurableRunner は IDurableRunner の実装です。
+type DurableRunner struct {
+	Processor IDurableProcessor
+	Logger    system.ILogger
+
+	// stop は 新しいバッチの開始を止めるためのチャネルです
+	stop chan struct{}
+	// cancel は 処理中のバッチを中断するための関数です
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDurableRunner は DurableRunner の新しいインスタンスを作成します。
+func NewDurableRunner(processor IDurableProcessor, logger system.ILogger) (IDurableRunner, error) {
+	return &DurableRunner{
+		Processor: processor,
+		Logger:    logger,
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Start は 購読者の処理をバックグラウンドで開始します。
+func (r *DurableRunner) Start(subscriber ISubscriber, interval time.Duration, batchSize int) {
+	// 停止の合図とは独立したコンテキストで処理し、バッチの途中で中断されないようにする
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer cancel()
+		r.Logger.SimpleInfoF("Starting subscriber: %s", subscriber.SubscriberName())
+
+		for {
+			processed, err := r.Processor.Process(ctx, subscriber, batchSize)
+			if err != nil {
+				r.Logger.WithFields(correlation.Fields(ctx)).WarnWithError(nil, err, map[string]interface{}{"subscriber": subscriber.SubscriberName()})
+			}
+
+			// 未処理のイベントが残っている可能性がある場合は待たずに続けて処理する
+			wait := interval
+			if err == nil && processed == batchSize {
+				wait = 0
+			}
+
+			select {
+			case <-r.stop:
+				r.Logger.SimpleInfoF("Subscriber stopped: %s", subscriber.SubscriberName())
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// Stop は 処理中のバッチの完了を待ってから停止します。
+func (r *DurableRunner) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if r.cancel != nil {
+			r.cancel()
+		}
+		<-done
+		return eris.Wrap(ctx.Err(), "subscriber did not stop before the deadline")
+	}
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"go.uber.org/mock/gomock"
+)
+
+// blockingProcessor は release が close されるまでバッチの処理を終えない IDurableProcessor です
+type blockingProcessor struct {
+	IDurableProcessor
+	started  chan struct{}
+	release  chan struct{}
+	batches  atomic.Int32
+	canceled atomic.Bool
+}
+
+func (p *blockingProcessor) Process(ctx context.Context, subscriber ISubscriber, batchSize int) (int, error) {
+	if p.batches.Add(1) == 1 {
+		close(p.started)
+	}
+	select {
+	case <-p.release:
+		return 0, nil
+	case <-ctx.Done():
+		p.canceled.Store(true)
+		return 0, ctx.Err()
+	}
+}
+
+func TestDurableRunner(t *testing.T) {
+	prepare := func(t *testing.T) (IDurableRunner, *blockingProcessor) {
+		ctrl := gomock.NewController(t)
+		logger := system.NewMockILogger(ctrl)
+		logger.EXPECT().SimpleInfoF(gomock.Any(), gomock.Any()).AnyTimes()
+		logger.EXPECT().WithFields(gomock.Any()).Return(logger).AnyTimes()
+		logger.EXPECT().WarnWithError(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+		processor := &blockingProcessor{started: make(chan struct{}), release: make(chan struct{})}
+		runner, err := NewDurableRunner(processor, logger)
+		assert.NoError(t, err)
+		return runner, processor
+	}
+
+	t.Run("処理中のバッチの完了を待ってから停止すること", func(t *testing.T) {
+		runner, processor := prepare(t)
+		runner.Start(&recordingSubscriber{name: "test"}, time.Hour, 100)
+		<-processor.started
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- runner.Stop(context.Background()) }()
+
+		select {
+		case <-stopped:
+			t.Fatal("Stop returned before the current batch completed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(processor.release)
+
+		assert.NoError(t, <-stopped)
+		assert.False(t, processor.canceled.Load())
+		assert.Equal(t, int32(1), processor.batches.Load())
+	})
+
+	t.Run("期限までにバッチが完了しない場合は処理を中断してエラーを返すこと", func(t *testing.T) {
+		runner, processor := prepare(t)
+		runner.Start(&recordingSubscriber{name: "test"}, time.Hour, 100)
+		<-processor.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := runner.Stop(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, processor.canceled.Load())
+	})
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/model"
+)
+
+// Event は 書き込み系の処理の結果として発生したドメインイベントを表すインターフェースです。
+type Event interface {
+	EventName() string
+}
+
+// ProductCreated は productが登録されたことを表すイベントです。
+type ProductCreated struct {
+	ProductID  uuid.UUID               `json:"product_id"`
+	TenantID   uuid.UUID               `json:"tenant_id"`
+	CategoryID uuid.UUID               `json:"category_id"`
+	Name       string                  `json:"name"`
+	Price      int64                   `json:"price"`
+	Properties model.ProductProperties `json:"properties"`
+	ListedAt   time.Time               `json:"listed_at"`
+}
+
+func (ProductCreated) EventName() string { return "ProductCreated" }
+
+// ProductUpdated は productが更新されたことを表すイベントです。
+// 価格が変更された場合は ProductPriceChanged も併せて発生します。
+type ProductUpdated struct {
+	ProductID  uuid.UUID               `json:"product_id"`
+	CategoryID uuid.UUID               `json:"category_id"`
+	Name       string                  `json:"name"`
+	Properties model.ProductProperties `json:"properties"`
+}
+
+func (ProductUpdated) EventName() string { return "ProductUpdated" }
+
+// ProductPriceChanged は productの価格が変更されたことを表すイベントです。
+type ProductPriceChanged struct {
+	ProductID uuid.UUID `json:"product_id"`
+	OldPrice  int64     `json:"old_price"`
+	NewPrice  int64     `json:"new_price"`
+}
+
+func (ProductPriceChanged) EventName() string { return "ProductPriceChanged" }
+
+// ProductDeleted は productが削除されたことを表すイベントです。
+type ProductDeleted struct {
+	ProductID uuid.UUID `json:"product_id"`
+}
+
+func (ProductDeleted) EventName() string { return "ProductDeleted" }
+
+// CategoryRenamed は categoryの名前が変更されたことを表すイベントです。
+type CategoryRenamed struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	OldName    string    `json:"old_name"`
+	NewName    string    `json:"new_name"`
+}
+
+func (CategoryRenamed) EventName() string { return "CategoryRenamed" }
+
+// decoders はイベント名から永続化されたイベントを復元する関数の一覧です
+var decoders = map[string]func(payload []byte) (Event, error){
+	ProductCreated{}.EventName():      decodeAs[ProductCreated],
+	ProductUpdated{}.EventName():      decodeAs[ProductUpdated],
+	ProductPriceChanged{}.EventName(): decodeAs[ProductPriceChanged],
+	ProductDeleted{}.EventName():      decodeAs[ProductDeleted],
+	CategoryRenamed{}.EventName():     decodeAs[CategoryRenamed],
+}
+
+// Decode は 永続化されたイベントのJSONを元のイベントの型に復元します。
+// 未知のイベント名の場合はエラーを返します。
+func Decode(name string, payload []byte) (Event, error) {
+	decode, ok := decoders[name]
+	if !ok {
+		return nil, eris.Errorf("unknown event: %s", name)
+	}
+	return decode(payload)
+}
+
+func decodeAs[E Event](payload []byte) (Event, error) {
+	var e E
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return e, nil
+}
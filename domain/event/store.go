@@ -0,0 +1,56 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package event
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+)
+
+// IStore は ドメインイベントをRDBに永続化するインターフェースです。
+// 永続購読者が書き込み処理と独立してイベントを読み出せるようにするために使用します。
+type IStore interface {
+	// Append は ドメインイベントを domain_events テーブルに追記します。
+	// 書き込み処理と同じトランザクションのクライアントを渡してください。
+	Append(ctx context.Context, client *ent.Client, events []Event) error
+}
+
+// Store は IStore の実装です。
+type Store struct {
+	Timer system.ITimer
+}
+
+// NewStore は Store の新しいインスタンスを作成します。
+func NewStore(timer system.ITimer) (IStore, error) {
+	return &Store{
+		Timer: timer,
+	}, nil
+}
+
+// Append は ドメインイベントを domain_events テーブルに追記します。
+func (s *Store) Append(ctx context.Context, client *ent.Client, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := s.Timer.Now()
+	builders := make([]*ent.DomainEventCreate, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		builders = append(builders, client.DomainEvent.Create().
+			SetName(e.EventName()).
+			SetPayload(payload).
+			SetOccurredAt(now))
+	}
+
+	if _, err := client.DomainEvent.CreateBulk(builders...).Save(ctx); err != nil {
+		return eris.Wrap(err, "")
+	}
+	return nil
+}
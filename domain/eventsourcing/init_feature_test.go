@@ -0,0 +1,10 @@
+package eventsourcing_test
+
+import (
+	"github.com/t-kuni/cqrs-example/testUtil"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	testUtil.TestMain(m)
+}
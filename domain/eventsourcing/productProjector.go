@@ -0,0 +1,165 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package eventsourcing
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/aggregate"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/aggregateevent"
+	"github.com/t-kuni/cqrs-example/ent/product"
+)
+
+// replayBatchSize は Replay で events テーブルから一度に読み出すイベントの件数です
+const replayBatchSize = 1000
+
+// IProductProjector は product のイベントストリームを products テーブルと OpenSearch に反映するインターフェースです。
+type IProductProjector interface {
+	// Project は イベントを products テーブルに反映します。
+	// afterVersion は最初のイベントの直前のシーケンス番号で、products の version は最後に反映したイベントのシーケンス番号になります。
+	// イベントを追記したトランザクションのクライアントを渡してください。
+	// OpenSearch へはコミット後にドメインイベントの購読者（ProductProjectionService）が反映します。
+	Project(ctx context.Context, client *ent.Client, afterVersion int64, events []event.Event) error
+
+	// Replay は events テーブルの全ての product のイベントを再生して products テーブルを再構築し、
+	// 対象の product を OpenSearch に同期し直します。再生した product の件数を返します。
+	Replay(ctx context.Context) (int, error)
+}
+
+// ProductProjector は IProductProjector の実装です。
+type ProductProjector struct {
+	DBConnector            db.IConnector
+	ProductTransferService service.IProductTransferService
+}
+
+// NewProductProjector は ProductProjector の新しいインスタンスを作成します。
+func NewProductProjector(conn db.IConnector, productTransferService service.IProductTransferService) (IProductProjector, error) {
+	return &ProductProjector{
+		DBConnector:            conn,
+		ProductTransferService: productTransferService,
+	}, nil
+}
+
+// Project は イベントを products テーブルに反映します。
+func (p *ProductProjector) Project(ctx context.Context, client *ent.Client, afterVersion int64, events []event.Event) error {
+	for i, e := range events {
+		if err := p.apply(ctx, client, afterVersion+int64(i)+1, e); err != nil {
+			return eris.Wrapf(err, "failed to project %s", e.EventName())
+		}
+	}
+	return nil
+}
+
+// Replay は events テーブルの全ての product のイベントを再生して products テーブルを再構築し、OpenSearch に同期し直します。
+func (p *ProductProjector) Replay(ctx context.Context) (int, error) {
+	// 集約ごとに最後のイベントが削除かどうかを保持し、OpenSearch の同期方法を決める
+	deleted := make(map[uuid.UUID]bool)
+
+	err := p.DBConnector.Transaction(ctx, func(ctx context.Context, tx *ent.Client) error {
+		lastID := int64(0)
+		for {
+			rows, err := tx.AggregateEvent.Query().
+				Where(
+					aggregateevent.AggregateType(aggregate.ProductAggregateType),
+					aggregateevent.IDGT(lastID),
+				).
+				Order(ent.Asc(aggregateevent.FieldID)).
+				Limit(replayBatchSize).
+				All(ctx)
+			if err != nil {
+				return eris.Wrap(err, "")
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+
+			for _, row := range rows {
+				e, err := event.Decode(row.Type, row.Payload)
+				if err != nil {
+					return eris.Wrap(err, "")
+				}
+				if err := p.apply(ctx, tx, row.Sequence, e); err != nil {
+					return eris.Wrapf(err, "failed to replay event %d", row.ID)
+				}
+				_, isDeleted := e.(event.ProductDeleted)
+				deleted[row.AggregateID] = isDeleted
+			}
+			lastID = rows[len(rows)-1].ID
+		}
+	})
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+
+	// 再構築した products テーブルはリードレプリカに反映されていない可能性があるため、プライマリから読んで同期する
+	ctx = db.WithPrimaryRead(ctx)
+	for productID, isDeleted := range deleted {
+		if isDeleted {
+			err = p.ProductTransferService.DeleteProduct(ctx, productID)
+		} else {
+			err = p.ProductTransferService.TransferProduct(ctx, productID)
+		}
+		if err != nil {
+			return 0, eris.Wrap(err, "")
+		}
+	}
+	return len(deleted), nil
+}
+
+// apply は イベントを1件 products テーブルに反映し、version をイベントのシーケンス番号にします
+// 1つのコマンドで複数のイベントが発生した場合も version が集約のシーケンス番号と一致するように、フックによるインクリメントは行いません
+func (p *ProductProjector) apply(ctx context.Context, client *ent.Client, sequence int64, e event.Event) error {
+	ctx = db.WithExplicitVersion(ctx)
+	switch ev := e.(type) {
+	case event.ProductCreated:
+		// 再生時に既に行が存在する場合も同じ結果になるように、存在する場合は上書きする
+		exists, err := client.Product.Query().Where(product.ID(ev.ProductID)).Exist(ctx)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if exists {
+			return client.Product.UpdateOneID(ev.ProductID).
+				SetTenantID(ev.TenantID).
+				SetCategoryID(ev.CategoryID).
+				SetName(ev.Name).
+				SetPrice(ev.Price).
+				SetProperties(&ev.Properties).
+				SetListedAt(ev.ListedAt).
+				SetVersion(sequence).
+				Exec(ctx)
+		}
+		return client.Product.Create().
+			SetID(ev.ProductID).
+			SetTenantID(ev.TenantID).
+			SetCategoryID(ev.CategoryID).
+			SetName(ev.Name).
+			SetPrice(ev.Price).
+			SetProperties(&ev.Properties).
+			SetListedAt(ev.ListedAt).
+			SetVersion(sequence).
+			Exec(ctx)
+	case event.ProductUpdated:
+		return client.Product.UpdateOneID(ev.ProductID).
+			SetCategoryID(ev.CategoryID).
+			SetName(ev.Name).
+			SetProperties(&ev.Properties).
+			SetVersion(sequence).
+			Exec(ctx)
+	case event.ProductPriceChanged:
+		return client.Product.UpdateOneID(ev.ProductID).
+			SetPrice(ev.NewPrice).
+			SetVersion(sequence).
+			Exec(ctx)
+	case event.ProductDeleted:
+		_, err := client.Product.Delete().
+			Where(product.ID(ev.ProductID)).
+			Exec(ctx)
+		return err
+	}
+	return nil
+}
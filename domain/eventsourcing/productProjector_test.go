@@ -0,0 +1,71 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/eventsourcing"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/testUtil"
+)
+
+func TestProductProjector_Project(t *testing.T) {
+	productID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	categoryID := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	t.Run("version を最後に反映したイベントのシーケンス番号にすること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.PrepareTestData(func(db *ent.Client) {
+			ctx := context.Background()
+			db.Tenant.Create().
+				SetID(tenantID).
+				SetOwnerID(uuid.MustParse("00000000-0000-0000-0000-000000000004")).
+				SetName("テナント1").
+				SaveX(ctx)
+			db.Category.Create().
+				SetID(categoryID).
+				SetName("カテゴリ1").
+				SaveX(ctx)
+		})
+
+		var testee eventsourcing.IProductProjector
+		var client *ent.Client
+		cont.Exec(func(p eventsourcing.IProductProjector, conn db.IConnector) {
+			testee = p
+			client = conn.GetEnt()
+		})
+
+		ctx := tenancy.WithoutTenantScope(context.Background())
+		err := testee.Project(ctx, client, 0, []event.Event{event.ProductCreated{
+			ProductID:  productID,
+			TenantID:   tenantID,
+			CategoryID: categoryID,
+			Name:       "商品1",
+			Price:      1000,
+			ListedAt:   testUtil.MustNewDateTime("2025-01-01T00:00:00Z"),
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), client.Product.GetX(ctx, productID).Version)
+
+		// 1つのコマンドで記録された2件のイベント
+		err = testee.Project(ctx, client, 1, []event.Event{
+			event.ProductUpdated{ProductID: productID, CategoryID: categoryID, Name: "商品2", Properties: model.ProductProperties{}},
+			event.ProductPriceChanged{ProductID: productID, OldPrice: 1000, NewPrice: 2000},
+		})
+		assert.NoError(t, err)
+
+		projected := client.Product.GetX(ctx, productID)
+		assert.Equal(t, int64(3), projected.Version)
+		assert.Equal(t, "商品2", projected.Name)
+		assert.Equal(t, int64(2000), projected.Price)
+	})
+}
@@ -0,0 +1,164 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/aggregate"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/aggregatesnapshot"
+)
+
+// DefaultSnapshotInterval は スナップショットを保存するイベント数の間隔のデフォルト値です。
+const DefaultSnapshotInterval = 50
+
+// IProductRepository は product の集約をイベントストアから読み込み、保存するインターフェースです。
+type IProductRepository interface {
+	// Load は スナップショットとその後のイベントから product の集約を復元します。
+	// イベントが1件もない場合は、イベントが1件もない状態の集約を返します（存在確認は Exists で行ってください）。
+	Load(ctx context.Context, client *ent.Client, id uuid.UUID) (*aggregate.Product, error)
+
+	// Save は 集約の新しいイベントをイベントストアに追記し、必要に応じてスナップショットを保存します。
+	// 読み込み後に他の処理が同じ集約を更新していた場合は ConcurrencyError を返します。
+	Save(ctx context.Context, client *ent.Client, p *aggregate.Product) error
+
+	// Adopt は イベントストリームを持たない products テーブルの行（シードや CRUD のモデルで登録されたもの）を集約として取り込みます。
+	// 行の version をシーケンス番号とする ProductCreated のイベントとスナップショットを保存するため、取り込み後も version は変わりません。
+	// 他の処理が同時に取り込んだ場合は ConcurrencyError を返します。
+	Adopt(ctx context.Context, client *ent.Client, row *ent.Product) (*aggregate.Product, error)
+}
+
+// ProductRepository は IProductRepository の実装です。
+type ProductRepository struct {
+	EventStore IEventStore
+	Timer      system.ITimer
+
+	// SnapshotInterval は スナップショットを保存するイベント数の間隔です。
+	SnapshotInterval int64
+}
+
+// NewProductRepository は ProductRepository の新しいインスタンスを作成します。
+func NewProductRepository(eventStore IEventStore, timer system.ITimer) (IProductRepository, error) {
+	return &ProductRepository{
+		EventStore:       eventStore,
+		Timer:            timer,
+		SnapshotInterval: DefaultSnapshotInterval,
+	}, nil
+}
+
+// Load は スナップショットとその後のイベントから product の集約を復元します。
+func (r *ProductRepository) Load(ctx context.Context, client *ent.Client, id uuid.UUID) (*aggregate.Product, error) {
+	snapshot, err := r.loadSnapshot(ctx, client, id)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	afterVersion := int64(0)
+	if snapshot != nil {
+		afterVersion = snapshot.Version
+	}
+	history, err := r.EventStore.Load(ctx, client, id, afterVersion)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	return aggregate.RebuildProduct(id, snapshot, history), nil
+}
+
+// Save は 集約の新しいイベントをイベントストアに追記し、必要に応じてスナップショットを保存します。
+func (r *ProductRepository) Save(ctx context.Context, client *ent.Client, p *aggregate.Product) error {
+	changes := p.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	expectedVersion := p.Version - int64(len(changes))
+	if err := r.EventStore.Append(ctx, client, aggregate.ProductAggregateType, p.ID, expectedVersion, changes); err != nil {
+		return eris.Wrap(err, "")
+	}
+	p.ClearChanges()
+
+	// 今回の追記で SnapshotInterval の倍数を跨いだ場合にスナップショットを保存する
+	if r.SnapshotInterval > 0 && expectedVersion/r.SnapshotInterval != p.Version/r.SnapshotInterval {
+		if err := r.saveSnapshot(ctx, client, p); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+// Adopt は イベントストリームを持たない products テーブルの行を集約として取り込みます。
+func (r *ProductRepository) Adopt(ctx context.Context, client *ent.Client, row *ent.Product) (*aggregate.Product, error) {
+	created := event.ProductCreated{
+		ProductID:  row.ID,
+		TenantID:   row.TenantID,
+		CategoryID: row.CategoryID,
+		Name:       row.Name,
+		Price:      row.Price,
+		ListedAt:   row.ListedAt,
+	}
+	if row.Properties != nil {
+		created.Properties = *row.Properties
+	}
+	if err := r.EventStore.Start(ctx, client, aggregate.ProductAggregateType, row.ID, row.Version, created); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	// ストリームはシーケンス番号 row.Version から始まるため、1から再生せずにスナップショットから復元されるようにする
+	p := aggregate.RebuildProduct(row.ID, nil, []event.Event{created})
+	p.Version = row.Version
+	if err := r.saveSnapshot(ctx, client, p); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return p, nil
+}
+
+func (r *ProductRepository) loadSnapshot(ctx context.Context, client *ent.Client, id uuid.UUID) (*aggregate.Product, error) {
+	row, err := client.AggregateSnapshot.Get(ctx, id)
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	var p aggregate.Product
+	if err := json.Unmarshal(row.State, &p); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return &p, nil
+}
+
+func (r *ProductRepository) saveSnapshot(ctx context.Context, client *ent.Client, p *aggregate.Product) error {
+	state, err := json.Marshal(p)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+
+	now := r.Timer.Now()
+	updated, err := client.AggregateSnapshot.Update().
+		Where(aggregatesnapshot.ID(p.ID)).
+		SetSequence(p.Version).
+		SetState(state).
+		SetCreatedAt(now).
+		Save(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	return client.AggregateSnapshot.Create().
+		SetID(p.ID).
+		SetAggregateType(aggregate.ProductAggregateType).
+		SetSequence(p.Version).
+		SetState(state).
+		SetCreatedAt(now).
+		Exec(ctx)
+}
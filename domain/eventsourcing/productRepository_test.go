@@ -0,0 +1,88 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/eventsourcing"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/testUtil"
+	"github.com/t-kuni/cqrs-example/util"
+)
+
+func TestProductRepository_Adopt(t *testing.T) {
+	productID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	categoryID := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	prepare := func(t *testing.T) (*testUtil.TestCaseContainer, eventsourcing.IProductRepository, *ent.Client) {
+		cont := testUtil.Prepare(t)
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		// イベントソーシング以外で登録され、3回更新された product
+		cont.PrepareTestData(func(db *ent.Client) {
+			db.Product.Create().
+				SetID(productID).
+				SetTenantID(tenantID).
+				SetCategoryID(categoryID).
+				SetName("商品1").
+				SetPrice(1000).
+				SetProperties(&model.ProductProperties{Size: util.Ptr("M")}).
+				SetListedAt(testUtil.MustNewDateTime("2025-01-01T00:00:00Z")).
+				SetVersion(3).
+				SaveX(context.Background())
+		})
+
+		var repository eventsourcing.IProductRepository
+		var client *ent.Client
+		cont.Exec(func(r eventsourcing.IProductRepository, conn db.IConnector) {
+			repository = r
+			client = conn.GetEnt()
+		})
+		return cont, repository, client
+	}
+
+	t.Run("行の version を引き継いで集約として取り込み、その後のイベントを追記できること", func(t *testing.T) {
+		cont, testee, client := prepare(t)
+		defer cont.Finish()
+
+		ctx := tenancy.WithoutTenantScope(context.Background())
+		row := client.Product.GetX(ctx, productID)
+
+		adopted, err := testee.Adopt(ctx, client, row)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), adopted.Version)
+		assert.Equal(t, "商品1", adopted.Name)
+
+		loaded, err := testee.Load(ctx, client, productID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), loaded.Version)
+		assert.True(t, loaded.Exists())
+
+		assert.NoError(t, loaded.Update(categoryID, "商品1", 2000, model.ProductProperties{}))
+		assert.NoError(t, testee.Save(ctx, client, loaded))
+		assert.Equal(t, int64(5), loaded.Version)
+	})
+
+	t.Run("既にイベントストリームがある場合は ConcurrencyError を返すこと", func(t *testing.T) {
+		cont, testee, client := prepare(t)
+		defer cont.Finish()
+
+		ctx := tenancy.WithoutTenantScope(context.Background())
+		row := client.Product.GetX(ctx, productID)
+
+		_, err := testee.Adopt(ctx, client, row)
+		assert.NoError(t, err)
+		_, err = testee.Adopt(ctx, client, row)
+
+		var concurrencyErr eventsourcing.ConcurrencyError
+		assert.True(t, eris.As(err, &concurrencyErr))
+		assert.Equal(t, int64(3), concurrencyErr.ActualVersion)
+	})
+}
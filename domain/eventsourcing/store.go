@@ -0,0 +1,187 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/aggregateevent"
+)
+
+// ConcurrencyError は 集約のイベントストリームが読み込み後に他の処理によって更新されていた場合のエラーです。
+// 呼び出し元は集約を読み込み直して処理をやり直してください。
+type ConcurrencyError struct {
+	AggregateID     uuid.UUID
+	ExpectedVersion int64
+	ActualVersion   int64
+}
+
+func (e ConcurrencyError) Error() string {
+	return fmt.Sprintf("aggregate %s was modified concurrently (expected version %d, actual version %d)", e.AggregateID, e.ExpectedVersion, e.ActualVersion)
+}
+
+// IEventStore は 集約ごとのイベントストリームを events テーブルに永続化するインターフェースです。
+type IEventStore interface {
+	// Load は 集約のイベントのうち、シーケンス番号が afterVersion より大きいものを古い順に返します。
+	Load(ctx context.Context, client *ent.Client, aggregateID uuid.UUID, afterVersion int64) ([]event.Event, error)
+
+	// Append は 集約のイベントストリームの末尾にイベントを追記します。
+	// ストリームの最新のシーケンス番号が expectedVersion と一致しない場合は ConcurrencyError を返します。
+	Append(ctx context.Context, client *ent.Client, aggregateType string, aggregateID uuid.UUID, expectedVersion int64, events []event.Event) error
+
+	// Start は イベントストリームを持たない集約のストリームを、version をシーケンス番号とするイベントで開始します。
+	// イベントソーシング以外で登録されたデータを、既存の version を引き継いでイベントストアに取り込むために使用します。
+	// 集約のイベントストリームが既に存在する場合は ConcurrencyError を返します。
+	Start(ctx context.Context, client *ent.Client, aggregateType string, aggregateID uuid.UUID, version int64, e event.Event) error
+}
+
+// EventStore は IEventStore の実装です。
+type EventStore struct {
+	Timer system.ITimer
+}
+
+// NewEventStore は EventStore の新しいインスタンスを作成します。
+func NewEventStore(timer system.ITimer) (IEventStore, error) {
+	return &EventStore{
+		Timer: timer,
+	}, nil
+}
+
+// Load は 集約のイベントのうち、シーケンス番号が afterVersion より大きいものを古い順に返します。
+func (s *EventStore) Load(ctx context.Context, client *ent.Client, aggregateID uuid.UUID, afterVersion int64) ([]event.Event, error) {
+	rows, err := client.AggregateEvent.Query().
+		Where(
+			aggregateevent.AggregateID(aggregateID),
+			aggregateevent.SequenceGT(afterVersion),
+		).
+		Order(ent.Asc(aggregateevent.FieldSequence)).
+		All(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	events := make([]event.Event, 0, len(rows))
+	for _, row := range rows {
+		e, err := event.Decode(row.Type, row.Payload)
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Append は 集約のイベントストリームの末尾にイベントを追記します。
+func (s *EventStore) Append(ctx context.Context, client *ent.Client, aggregateType string, aggregateID uuid.UUID, expectedVersion int64, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	actualVersion, err := s.currentVersion(ctx, client, aggregateID)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if actualVersion != expectedVersion {
+		return eris.Wrap(ConcurrencyError{
+			AggregateID:     aggregateID,
+			ExpectedVersion: expectedVersion,
+			ActualVersion:   actualVersion,
+		}, "")
+	}
+
+	now := s.Timer.Now()
+	builders := make([]*ent.AggregateEventCreate, 0, len(events))
+	for i, e := range events {
+		builder, err := s.newEvent(client, aggregateType, aggregateID, expectedVersion+int64(i)+1, e, now)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		builders = append(builders, builder)
+	}
+
+	_, err = client.AggregateEvent.CreateBulk(builders...).Save(ctx)
+	// 確認後に他の処理が同じシーケンス番号で追記した場合は一意制約違反になる
+	if ent.IsConstraintError(err) {
+		return s.concurrencyError(ctx, client, aggregateID, expectedVersion)
+	}
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	return nil
+}
+
+// Start は イベントストリームを持たない集約のストリームを、version をシーケンス番号とするイベントで開始します。
+func (s *EventStore) Start(ctx context.Context, client *ent.Client, aggregateType string, aggregateID uuid.UUID, version int64, e event.Event) error {
+	actualVersion, err := s.currentVersion(ctx, client, aggregateID)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	if actualVersion != 0 {
+		return eris.Wrap(ConcurrencyError{
+			AggregateID:     aggregateID,
+			ExpectedVersion: 0,
+			ActualVersion:   actualVersion,
+		}, "")
+	}
+
+	builder, err := s.newEvent(client, aggregateType, aggregateID, version, e, s.Timer.Now())
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	err = builder.Exec(ctx)
+	// 確認後に他の処理が同じ集約のストリームを開始した場合は一意制約違反になる
+	if ent.IsConstraintError(err) {
+		return s.concurrencyError(ctx, client, aggregateID, 0)
+	}
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	return nil
+}
+
+func (s *EventStore) newEvent(client *ent.Client, aggregateType string, aggregateID uuid.UUID, sequence int64, e event.Event, now time.Time) (*ent.AggregateEventCreate, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return client.AggregateEvent.Create().
+		SetAggregateType(aggregateType).
+		SetAggregateID(aggregateID).
+		SetSequence(sequence).
+		SetType(e.EventName()).
+		SetPayload(payload).
+		SetOccurredAt(now), nil
+}
+
+func (s *EventStore) concurrencyError(ctx context.Context, client *ent.Client, aggregateID uuid.UUID, expectedVersion int64) error {
+	actualVersion, err := s.currentVersion(ctx, client, aggregateID)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	return eris.Wrap(ConcurrencyError{
+		AggregateID:     aggregateID,
+		ExpectedVersion: expectedVersion,
+		ActualVersion:   actualVersion,
+	}, "")
+}
+
+func (s *EventStore) currentVersion(ctx context.Context, client *ent.Client, aggregateID uuid.UUID) (int64, error) {
+	last, err := client.AggregateEvent.Query().
+		Where(aggregateevent.AggregateID(aggregateID)).
+		Order(ent.Desc(aggregateevent.FieldSequence)).
+		First(ctx)
+	if ent.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, eris.Wrap(err, "")
+	}
+	return last.Sequence, nil
+}
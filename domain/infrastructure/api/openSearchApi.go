@@ -20,4 +20,49 @@ type IOpenSearchApi interface {
 	// Returns:
 	//   - error: エラーが発生した場合
 	IndexDocument(ctx context.Context, indexName string, documentID string, document string) error
+
+	// DeleteDocument は OpenSearch からドキュメントを削除します。
+	// 指定したドキュメントIDが存在しない場合はエラーになりません。
+	//
+	// Parameters:
+	//   - ctx: コンテキスト
+	//   - indexName: インデックス名
+	//   - documentID: ドキュメントID
+	//
+	// Returns:
+	//   - error: エラーが発生した場合
+	DeleteDocument(ctx context.Context, indexName string, documentID string) error
+
+	// Search は OpenSearch でドキュメントを検索します。
+	//
+	// Parameters:
+	//   - ctx: コンテキスト
+	//   - indexName: インデックス名
+	//   - query: JSON形式の検索クエリ文字列
+	//
+	// Returns:
+	//   - string: JSON形式の検索結果文字列
+	//   - error: エラーが発生した場合
+	Search(ctx context.Context, indexName string, query string) (string, error)
+
+	// ClusterHealth は OpenSearch クラスタのヘルスステータス（green / yellow / red）を返します。
+	//
+	// Parameters:
+	//   - ctx: コンテキスト
+	//
+	// Returns:
+	//   - string: クラスタのヘルスステータス
+	//   - error: エラーが発生した場合
+	ClusterHealth(ctx context.Context) (string, error)
+
+	// IndexExists は インデックスが存在するかどうかを返します。
+	//
+	// Parameters:
+	//   - ctx: コンテキスト
+	//   - indexName: インデックス名
+	//
+	// Returns:
+	//   - bool: インデックスが存在する場合は true
+	//   - error: エラーが発生した場合
+	IndexExists(ctx context.Context, indexName string) (bool, error)
 }
@@ -3,13 +3,69 @@ package db
 import (
 	"context"
 	"database/sql"
-	"entgo.io/ent/dialect/sql/schema"
 	"github.com/t-kuni/cqrs-example/ent"
 )
 
 type IConnector interface {
 	GetDB() *sql.DB
 	GetEnt() *ent.Client
-	Transaction(ctx context.Context, fn func(tx *ent.Client) error) error
-	Migrate(ctx context.Context, opts ...schema.MigrateOption) error
+	// GetReplicaEnt は 参照系のクエリに使用するリードレプリカの ent クライアントを返します
+	// リードレプリカが設定されていない場合は GetEnt と同じクライアントを返します
+	// レプリケーションの遅延があるため、書き込んだ直後のデータを読む場合は GetEnt を使用してください
+	GetReplicaEnt() *ent.Client
+	// Transaction は fn をトランザクション内で実行し、fn がエラーを返した場合はロールバックします
+	// fn に渡すコンテキストからは TxClient でトランザクションのクライアントを取得できます
+	// 既にトランザクション内のコンテキストで呼び出した場合は SAVEPOINT を作成し、fn のエラー時はその時点までロールバックします
+	// デッドロックやロック待ちのタイムアウトで失敗した場合は、最も外側のトランザクションを fn ごとやり直します
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx *ent.Client) error) error
+}
+
+type txClientKey struct{}
+
+type primaryReadKey struct{}
+
+type explicitVersionKey struct{}
+
+// WithTxClient は トランザクションのクライアントを保持するコンテキストを返します
+// IConnector の実装から使用します
+func WithTxClient(ctx context.Context, client *ent.Client) context.Context {
+	return context.WithValue(ctx, txClientKey{}, client)
+}
+
+// TxClient は コンテキストで実行中のトランザクションのクライアントを返します
+// トランザクション外の場合は false を返します
+func TxClient(ctx context.Context) (*ent.Client, bool) {
+	client, ok := ctx.Value(txClientKey{}).(*ent.Client)
+	return client, ok
+}
+
+// WithPrimaryRead は GetReplicaEnt の代わりにプライマリから読むべきことを示すコンテキストを返します
+// コミット直後のデータを読む射影の更新などで使用します
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// WithExplicitVersion は version の検証とインクリメントを行わず、SetVersion の値をそのまま保存するコンテキストを返します
+// イベントのシーケンス番号を version とする射影の更新で使用します
+func WithExplicitVersion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explicitVersionKey{}, true)
+}
+
+// IsExplicitVersion は WithExplicitVersion が指定されているかを返します
+func IsExplicitVersion(ctx context.Context) bool {
+	explicit, _ := ctx.Value(explicitVersionKey{}).(bool)
+	return explicit
+}
+
+// ReadEnt は 参照系のクエリに使用する ent クライアントを返します
+// トランザクション内の場合はトランザクションのクライアント、WithPrimaryRead が指定されている場合はプライマリ、
+// それ以外の場合はリードレプリカのクライアントです
+func ReadEnt(ctx context.Context, conn IConnector) *ent.Client {
+	if tx, ok := TxClient(ctx); ok {
+		return tx
+	}
+	if primary, _ := ctx.Value(primaryReadKey{}).(bool); primary {
+		return conn.GetEnt()
+	}
+	return conn.GetReplicaEnt()
 }
@@ -0,0 +1,54 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDatabaseAhead は DBにコードに存在しないマイグレーションが適用されている場合のエラーです
+// 新しいバージョンのアプリケーションで適用したDBに対して、古いコードからマイグレーションを実行しようとした場合などに発生します
+var ErrDatabaseAhead = errors.New("database is ahead of the code")
+
+// ErrMigrationModified は 適用済みのマイグレーションファイルの内容が変更されている場合のエラーです
+var ErrMigrationModified = errors.New("applied migration has been modified")
+
+// Migration は バージョン付きのマイグレーションファイル（<バージョン>_<名前>.up.sql と .down.sql）です
+type Migration struct {
+	// Version は ファイル名の先頭のバージョン（生成した日時の yyyyMMddHHmmss）です
+	Version string
+	Name    string
+	// Up と Down は 適用とロールバックで実行するSQL文です
+	Up   []string
+	Down []string
+	// Checksum は up.sql の SHA-256 です。適用後にファイルが変更されていないかの確認に使用します
+	Checksum string
+}
+
+// MigrationStatus は マイグレーションの適用状況です
+type MigrationStatus struct {
+	Version string
+	Name    string
+	// AppliedAt は 適用した日時です（未適用の場合は nil）
+	AppliedAt *time.Time
+	// Unknown は DBに適用済みだがコードに存在しないマイグレーションであることを表します
+	// DBがコードより新しい状態のため、up と down は実行を拒否します
+	Unknown bool
+	// Modified は 適用後にファイルの内容が変更されたことを表します
+	Modified bool
+}
+
+// IMigrator は バージョン付きのマイグレーションを適用・ロールバックするインターフェースです
+// 適用履歴は DB の schema_migrations テーブルに記録します
+type IMigrator interface {
+	// Status は コードとDBの全てのマイグレーションの適用状況をバージョン順に返します
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	// Up は 未適用のマイグレーションを古い順に最大 steps 件（0 の場合は全て）適用し、適用したマイグレーションを返します
+	// dryRun の場合は実行せずに、適用するマイグレーションを返します
+	Up(ctx context.Context, steps int, dryRun bool) ([]Migration, error)
+	// Down は 適用済みのマイグレーションを新しい順に最大 steps 件（0 の場合は全て）ロールバックし、ロールバックしたマイグレーションを返します
+	// dryRun の場合は実行せずに、ロールバックするマイグレーションを返します
+	Down(ctx context.Context, steps int, dryRun bool) ([]Migration, error)
+}
@@ -0,0 +1,10 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+
+package system
+
+import "github.com/t-kuni/cqrs-example/domain/auth"
+
+// IAuthenticator は Bearer トークンを検証して認証済みの主体を返します
+type IAuthenticator interface {
+	Authenticate(token string) (*auth.Principal, error)
+}
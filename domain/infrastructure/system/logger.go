@@ -11,6 +11,9 @@ import (
 // ログ出力に関する機能を提供します
 // アプリケーション内でのログ出力に使用することを想定しています
 type ILogger interface {
+	// WithFields 全てのログに指定したフィールドを付与するロガーを返します
+	WithFields(fields map[string]interface{}) ILogger
+
 	// Info 情報ログを出力します
 	Info(req *http.Request, msg string, params map[string]interface{})
 
@@ -52,4 +55,8 @@ type ILogger interface {
 
 	// ResponseLogV2 レスポンスログを出力します（V2）
 	ResponseLogV2(req *http.Request, status int, latency time.Duration, latencyHuman string)
+
+	// Flush 出力済みのログを出力先に書き出します
+	// プロセスの終了前に呼び出し、ログの欠落を防ぎます
+	Flush() error
 }
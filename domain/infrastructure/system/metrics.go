@@ -0,0 +1,24 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+
+package system
+
+import "time"
+
+// IMetrics メトリクスインターフェース
+// アプリケーションの稼働状況を表すメトリクスを記録します（/metrics で公開されます）
+type IMetrics interface {
+	// ObserveHTTPRequest HTTPリクエストの件数と処理時間を、ルート（パスのパターン）とステータスコードごとに記録します
+	ObserveHTTPRequest(method string, route string, status int, latency time.Duration)
+
+	// ObserveOpenSearchRequest OpenSearch の操作の処理時間とエラーの件数を記録します
+	ObserveOpenSearchRequest(operation string, latency time.Duration, err error)
+
+	// IncProjectionIndexed OpenSearch に同期したドキュメントの件数を加算します
+	IncProjectionIndexed()
+
+	// IncProjectionFailures OpenSearch への同期に失敗した件数を加算します
+	IncProjectionFailures()
+
+	// SetProjectionLag OpenSearch への同期が済んでいないドキュメントの件数を設定します
+	SetProjectionLag(documents int)
+}
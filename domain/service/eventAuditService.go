@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// EventAuditService は 全てのドメインイベントを監査ログとして出力する event.ISubscriber の実装です。
+// 書き込み処理の応答時間に影響を与えないよう、永続購読者として subscribeEvents コマンドから呼び出されることを想定しています。
+type EventAuditService struct {
+	Logger system.ILogger
+}
+
+// NewEventAuditService は EventAuditService の新しいインスタンスを作成します。
+func NewEventAuditService(logger system.ILogger) (event.ISubscriber, error) {
+	return &EventAuditService{
+		Logger: logger,
+	}, nil
+}
+
+// SubscriberName は 購読者を識別する名前を返します。
+func (s *EventAuditService) SubscriberName() string {
+	return "event-audit"
+}
+
+// Handle は ドメインイベントの内容を監査ログとして出力します。
+func (s *EventAuditService) Handle(ctx context.Context, e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+
+	s.Logger.WithFields(correlation.Fields(ctx)).Info(nil, "[Event]"+e.EventName(), map[string]interface{}{
+		"event":   e.EventName(),
+		"payload": string(payload),
+	})
+	return nil
+}
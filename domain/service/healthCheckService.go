@@ -0,0 +1,200 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+)
+
+const (
+	// HealthStatusUp は 正常に稼働していることを表します。
+	HealthStatusUp = "up"
+	// HealthStatusDown は 稼働していない（リクエストを受け付けられない）ことを表します。
+	HealthStatusDown = "down"
+)
+
+// healthCheckTimeout は 依存先ごとのチェックのタイムアウトです。
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck は 依存先ごとのチェック結果です。
+type HealthCheck struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport は ヘルスチェックの結果です。
+// いずれかの依存先が down の場合は全体も down になります。
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// IsUp は 全体のステータスが up かどうかを返します。
+func (r HealthReport) IsUp() bool {
+	return r.Status == HealthStatusUp
+}
+
+// IHealthCheckService は liveness / readiness プローブのためのヘルスチェックを提供するインターフェースです。
+type IHealthCheckService interface {
+	// Liveness は プロセスが稼働しているかを返します。
+	// 依存先の障害でプロセスが再起動されないよう、依存先はチェックしません。
+	Liveness(ctx context.Context) HealthReport
+
+	// Readiness は リクエストを受け付けられるかを、MySQL・OpenSearch・永続購読者の未処理のイベントの件数をチェックして返します。
+	// MarkNotReady が呼び出された後は常に down を返します。
+	Readiness(ctx context.Context) HealthReport
+
+	// MarkNotReady は 以降の Readiness を down にします。
+	// シャットダウンの開始時に呼び出し、ロードバランサから切り離されるようにします。
+	MarkNotReady()
+}
+
+// HealthCheckService は IHealthCheckService の実装です。
+type HealthCheckService struct {
+	DBConnector        db.IConnector
+	OpenSearchApi      api.IOpenSearchApi
+	DurableProcessor   event.IDurableProcessor
+	DurableSubscribers []event.ISubscriber
+	// MaxProjectionLag は 永続購読者ごとに許容する未処理のイベントの件数です。
+	MaxProjectionLag int
+
+	notReady atomic.Bool
+}
+
+// NewHealthCheckService は HealthCheckService の新しいインスタンスを作成します。
+// 許容する未処理のイベントの件数は設定の Sync.ReadinessMaxProjectionLag です。
+func NewHealthCheckService(
+	cfg *config.Config,
+	conn db.IConnector,
+	openSearchApi api.IOpenSearchApi,
+	durableProcessor event.IDurableProcessor,
+	durableSubscribers []event.ISubscriber,
+) (IHealthCheckService, error) {
+	return &HealthCheckService{
+		DBConnector:        conn,
+		OpenSearchApi:      openSearchApi,
+		DurableProcessor:   durableProcessor,
+		DurableSubscribers: durableSubscribers,
+		MaxProjectionLag:   cfg.Sync.ReadinessMaxProjectionLag,
+	}, nil
+}
+
+// Liveness は プロセスが稼働しているかを返します。
+func (s *HealthCheckService) Liveness(ctx context.Context) HealthReport {
+	return HealthReport{Status: HealthStatusUp}
+}
+
+// Readiness は リクエストを受け付けられるかを返します。
+func (s *HealthCheckService) Readiness(ctx context.Context) HealthReport {
+	if s.notReady.Load() {
+		return HealthReport{
+			Status: HealthStatusDown,
+			Checks: map[string]HealthCheck{
+				"shutdown": {Status: HealthStatusDown, Error: "server is shutting down"},
+			},
+		}
+	}
+
+	checks := map[string]func(ctx context.Context) HealthCheck{
+		"mysql":            s.checkMySQL,
+		"opensearch":       s.checkOpenSearch,
+		"opensearch_index": s.checkOpenSearchIndex,
+		"projection":       s.checkProjectionLag,
+	}
+
+	report := HealthReport{
+		Status: HealthStatusUp,
+		Checks: make(map[string]HealthCheck, len(checks)),
+	}
+
+	// 依存先ごとのチェックは並行して実行し、プローブの応答時間をタイムアウト以内に収める
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			result := check(checkCtx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != HealthStatusUp {
+				report.Status = HealthStatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// MarkNotReady は 以降の Readiness を down にします。
+func (s *HealthCheckService) MarkNotReady() {
+	s.notReady.Store(true)
+}
+
+func (s *HealthCheckService) checkMySQL(ctx context.Context) HealthCheck {
+	if err := s.DBConnector.GetDB().PingContext(ctx); err != nil {
+		return downCheck(err, nil)
+	}
+	return HealthCheck{Status: HealthStatusUp}
+}
+
+func (s *HealthCheckService) checkOpenSearch(ctx context.Context) HealthCheck {
+	status, err := s.OpenSearchApi.ClusterHealth(ctx)
+	if err != nil {
+		return downCheck(err, nil)
+	}
+	details := map[string]interface{}{"cluster_status": status}
+	// yellow はレプリカが割り当てられていないだけで検索・登録はできるため up とする
+	if status == "red" {
+		return HealthCheck{Status: HealthStatusDown, Error: "cluster status is red", Details: details}
+	}
+	return HealthCheck{Status: HealthStatusUp, Details: details}
+}
+
+func (s *HealthCheckService) checkOpenSearchIndex(ctx context.Context) HealthCheck {
+	details := map[string]interface{}{"index": "products"}
+	exists, err := s.OpenSearchApi.IndexExists(ctx, "products")
+	if err != nil {
+		return downCheck(err, details)
+	}
+	if !exists {
+		return HealthCheck{Status: HealthStatusDown, Error: "index does not exist", Details: details}
+	}
+	return HealthCheck{Status: HealthStatusUp, Details: details}
+}
+
+// checkProjectionLag は 永続購読者ごとの未処理のイベントの件数が許容範囲内かをチェックします。
+func (s *HealthCheckService) checkProjectionLag(ctx context.Context) HealthCheck {
+	details := map[string]interface{}{"max_lag": s.MaxProjectionLag}
+	result := HealthCheck{Status: HealthStatusUp, Details: details}
+
+	for _, subscriber := range s.DurableSubscribers {
+		lag, err := s.DurableProcessor.Lag(ctx, subscriber.SubscriberName())
+		if err != nil {
+			return downCheck(err, details)
+		}
+		details[subscriber.SubscriberName()] = lag
+		if lag > s.MaxProjectionLag {
+			result.Status = HealthStatusDown
+			result.Error = "projection lag exceeds the limit"
+		}
+	}
+	return result
+}
+
+func downCheck(err error, details map[string]interface{}) HealthCheck {
+	return HealthCheck{Status: HealthStatusDown, Error: err.Error(), Details: details}
+}
@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	"go.uber.org/mock/gomock"
+)
+
+// pingDriver は Ping の結果だけを返す database/sql のドライバです
+type pingDriver struct {
+	err error
+}
+
+func (d pingDriver) Open(name string) (driver.Conn, error) { return pingConn(d), nil }
+
+type pingConn struct {
+	err error
+}
+
+func (c pingConn) Ping(ctx context.Context) error            { return c.err }
+func (c pingConn) Prepare(query string) (driver.Stmt, error) { return nil, eris.New("not supported") }
+func (c pingConn) Close() error                              { return nil }
+func (c pingConn) Begin() (driver.Tx, error)                 { return nil, eris.New("not supported") }
+
+func init() {
+	sql.Register("health-check-up", pingDriver{})
+	sql.Register("health-check-down", pingDriver{err: eris.New("connection refused")})
+}
+
+// stubConnector は GetDB だけを提供する db.IConnector です
+type stubConnector struct {
+	db.IConnector
+	sqlDB *sql.DB
+}
+
+func (c stubConnector) GetDB() *sql.DB { return c.sqlDB }
+
+type namedSubscriber string
+
+func (s namedSubscriber) SubscriberName() string                          { return string(s) }
+func (s namedSubscriber) Handle(ctx context.Context, e event.Event) error { return nil }
+
+func TestHealthCheckService_Readiness(t *testing.T) {
+	prepare := func(t *testing.T, driverName string) (db.IConnector, *api.MockIOpenSearchApi, *event.MockIDurableProcessor) {
+		ctrl := gomock.NewController(t)
+		sqlDB, err := sql.Open(driverName, "")
+		assert.NoError(t, err)
+		t.Cleanup(func() { sqlDB.Close() })
+
+		return stubConnector{sqlDB: sqlDB}, api.NewMockIOpenSearchApi(ctrl), event.NewMockIDurableProcessor(ctrl)
+	}
+
+	t.Run("全ての依存先が正常な場合はupを返すこと", func(t *testing.T) {
+		cfg := &config.Config{Sync: config.Sync{ReadinessMaxProjectionLag: 10000}}
+		conn, openSearchApi, processor := prepare(t, "health-check-up")
+		openSearchApi.EXPECT().ClusterHealth(gomock.Any()).Return("yellow", nil)
+		openSearchApi.EXPECT().IndexExists(gomock.Any(), "products").Return(true, nil)
+		processor.EXPECT().Lag(gomock.Any(), "event-audit").Return(3, nil)
+
+		testee, err := service.NewHealthCheckService(cfg, conn, openSearchApi, processor, []event.ISubscriber{namedSubscriber("event-audit")})
+		assert.NoError(t, err)
+
+		report := testee.Readiness(context.Background())
+
+		assert.True(t, report.IsUp())
+		assert.Equal(t, service.HealthStatusUp, report.Checks["mysql"].Status)
+		assert.Equal(t, "yellow", report.Checks["opensearch"].Details["cluster_status"])
+		assert.Equal(t, service.HealthStatusUp, report.Checks["opensearch_index"].Status)
+		assert.Equal(t, 3, report.Checks["projection"].Details["event-audit"])
+	})
+
+	t.Run("依存先が異常な場合は依存先ごとにdownを返すこと", func(t *testing.T) {
+		cfg := &config.Config{Sync: config.Sync{ReadinessMaxProjectionLag: 10}}
+		conn, openSearchApi, processor := prepare(t, "health-check-down")
+		openSearchApi.EXPECT().ClusterHealth(gomock.Any()).Return("red", nil)
+		openSearchApi.EXPECT().IndexExists(gomock.Any(), "products").Return(false, nil)
+		processor.EXPECT().Lag(gomock.Any(), "event-audit").Return(11, nil)
+
+		testee, err := service.NewHealthCheckService(cfg, conn, openSearchApi, processor, []event.ISubscriber{namedSubscriber("event-audit")})
+		assert.NoError(t, err)
+
+		report := testee.Readiness(context.Background())
+
+		assert.False(t, report.IsUp())
+		assert.Equal(t, service.HealthStatusDown, report.Checks["mysql"].Status)
+		assert.Contains(t, report.Checks["mysql"].Error, "connection refused")
+		assert.Equal(t, service.HealthStatusDown, report.Checks["opensearch"].Status)
+		assert.Equal(t, service.HealthStatusDown, report.Checks["opensearch_index"].Status)
+		assert.Equal(t, service.HealthStatusDown, report.Checks["projection"].Status)
+	})
+
+	t.Run("MarkNotReadyの後は依存先をチェックせずにdownを返すこと", func(t *testing.T) {
+		cfg := &config.Config{}
+		conn, openSearchApi, processor := prepare(t, "health-check-up")
+
+		testee, err := service.NewHealthCheckService(cfg, conn, openSearchApi, processor, nil)
+		assert.NoError(t, err)
+		testee.MarkNotReady()
+
+		report := testee.Readiness(context.Background())
+
+		assert.False(t, report.IsUp())
+		assert.Equal(t, service.HealthStatusDown, report.Checks["shutdown"].Status)
+		assert.True(t, testee.Liveness(context.Background()).IsUp())
+	})
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/event"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent/product"
+)
+
+// ProductProjectionService は productに関するドメインイベントを OpenSearch に反映する event.ISubscriber の実装です。
+// コマンドのコミット後にプロセス内で同期的に呼び出されることを想定しています。
+type ProductProjectionService struct {
+	DBConnector            db.IConnector
+	ProductTransferService IProductTransferService
+}
+
+// NewProductProjectionService は ProductProjectionService の新しいインスタンスを作成します。
+func NewProductProjectionService(conn db.IConnector, productTransferService IProductTransferService) (event.ISubscriber, error) {
+	return &ProductProjectionService{
+		DBConnector:            conn,
+		ProductTransferService: productTransferService,
+	}, nil
+}
+
+// SubscriberName は 購読者を識別する名前を返します。
+func (s *ProductProjectionService) SubscriberName() string {
+	return "product-projection"
+}
+
+// Handle は productに関するドメインイベントを OpenSearch に反映します。
+// categoryの名前が変更された場合は、そのcategoryに属する全productを再同期します。
+func (s *ProductProjectionService) Handle(ctx context.Context, e event.Event) error {
+	// コミット直後のデータはリードレプリカに反映されていない可能性があるため、プライマリから読む
+	ctx = db.WithPrimaryRead(ctx)
+
+	switch ev := e.(type) {
+	case event.ProductCreated:
+		return s.transfer(ctx, ev.ProductID)
+	case event.ProductUpdated:
+		return s.transfer(ctx, ev.ProductID)
+	case event.ProductPriceChanged:
+		// ProductUpdated と同じコマンドで発生するため、ProductUpdated 側で同期済み
+		return nil
+	case event.ProductDeleted:
+		if err := s.ProductTransferService.DeleteProduct(ctx, ev.ProductID); err != nil {
+			return eris.Wrap(err, "")
+		}
+	case event.CategoryRenamed:
+		// categoryは全テナントで共有されているため、テナントを跨いで再同期する
+		ctx = tenancy.WithoutTenantScope(ctx)
+		productIDs, err := s.DBConnector.GetEnt().Product.
+			Query().
+			Where(product.CategoryID(ev.CategoryID)).
+			IDs(ctx)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		for _, productID := range productIDs {
+			if err := s.transfer(ctx, productID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ProductProjectionService) transfer(ctx context.Context, productID uuid.UUID) error {
+	if err := s.ProductTransferService.TransferProduct(ctx, productID); err != nil {
+		return eris.Wrap(err, "")
+	}
+	return nil
+}
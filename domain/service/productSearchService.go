@@ -0,0 +1,137 @@
+//go:generate go tool mockgen -source=$GOFILE -destination=${GOFILE}_mock.go -package=$GOPACKAGE
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+)
+
+// ProductDocument は OpenSearch の products インデックスのドキュメントです。
+// spec/openSearchScheme/products.json を参照
+type ProductDocument struct {
+	ID         string                  `json:"id"`
+	Name       string                  `json:"name"`
+	Price      int64                   `json:"price"`
+	ListedAt   time.Time               `json:"listed_at"`
+	Properties model.ProductProperties `json:"properties"`
+	Tenant     struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"tenant"`
+	Category struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"category"`
+}
+
+// ProductSearchResult は product の検索結果です。
+type ProductSearchResult struct {
+	Total    int64
+	Products []ProductDocument
+}
+
+// IProductSearchService は OpenSearch で product を検索するサービスのインターフェースです。
+// コンテキストがテナントの範囲に制限されている場合は、そのテナントの product のみを検索します。
+type IProductSearchService interface {
+	// Search は 名前がキーワードに一致する product を出品日時の新しい順に検索します。
+	// キーワードが空の場合は全件を対象にします。
+	// テナントを解決できていないコンテキストの場合は tenancy.ErrTenantRequired を返します。
+	Search(ctx context.Context, keyword string, from int, size int) (*ProductSearchResult, error)
+}
+
+// ProductSearchService は IProductSearchService の実装です。
+type ProductSearchService struct {
+	OpenSearchApi api.IOpenSearchApi
+}
+
+// NewProductSearchService は ProductSearchService の新しいインスタンスを作成します。
+func NewProductSearchService(openSearchApi api.IOpenSearchApi) (IProductSearchService, error) {
+	return &ProductSearchService{
+		OpenSearchApi: openSearchApi,
+	}, nil
+}
+
+// Search は 名前がキーワードに一致する product を出品日時の新しい順に検索します。
+func (s *ProductSearchService) Search(ctx context.Context, keyword string, from int, size int) (*ProductSearchResult, error) {
+	must := []interface{}{
+		map[string]interface{}{"match_all": map[string]interface{}{}},
+	}
+	if keyword != "" {
+		must = []interface{}{
+			map[string]interface{}{"match": map[string]interface{}{"name": keyword}},
+		}
+	}
+
+	filter, err := tenantFilter(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	query, err := json.Marshal(map[string]interface{}{
+		"from":             from,
+		"size":             size,
+		"track_total_hits": true,
+		"sort": []interface{}{
+			map[string]interface{}{"listed_at": "desc"},
+			map[string]interface{}{"id": "asc"},
+		},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   must,
+				"filter": filter,
+			},
+		},
+	})
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	resBody, err := s.OpenSearchApi.Search(ctx, "products", string(query))
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	var res struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source ProductDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal([]byte(resBody), &res); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	result := &ProductSearchResult{
+		Total:    res.Hits.Total.Value,
+		Products: make([]ProductDocument, 0, len(res.Hits.Hits)),
+	}
+	for _, hit := range res.Hits.Hits {
+		result.Products = append(result.Products, hit.Source)
+	}
+	return result, nil
+}
+
+// tenantFilter は コンテキストのテナントに限定する検索条件を返します
+// テナントの範囲に制限されていない場合は空の条件を返します
+func tenantFilter(ctx context.Context) ([]interface{}, error) {
+	tenantID, ok, err := tenancy.Resolve(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if !ok {
+		return []interface{}{}, nil
+	}
+	return []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"tenant.id": tenantID.String()}},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProductSearchService_Search(t *testing.T) {
+	const resBody = `{"hits":{"total":{"value":1},"hits":[{"_source":{"id":"p1","name":"Shirt","price":1000,"listed_at":"2025-01-02T03:04:05Z","tenant":{"id":"t1"}}}]}}`
+
+	t.Run("テナントの範囲に制限されている場合はテナントの条件が付与されること", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tenantID := uuid.MustParse("00000000-0000-0000-0000-000000000011")
+
+		openSearchApi := api.NewMockIOpenSearchApi(ctrl)
+		openSearchApi.EXPECT().
+			Search(gomock.Any(), "products", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, indexName string, query string) (string, error) {
+				assert.Contains(t, query, `"filter":[{"term":{"tenant.id":"00000000-0000-0000-0000-000000000011"}}]`)
+				assert.Contains(t, query, `{"match":{"name":"Shirt"}}`)
+				return resBody, nil
+			})
+
+		testee, err := service.NewProductSearchService(openSearchApi)
+		assert.NoError(t, err)
+
+		result, err := testee.Search(tenancy.WithTenantID(context.Background(), tenantID), "Shirt", 0, 20)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Total)
+		assert.Equal(t, "p1", result.Products[0].ID)
+		assert.Equal(t, "t1", result.Products[0].Tenant.ID)
+	})
+
+	t.Run("テナントの範囲に制限されていない場合はテナントの条件が付与されないこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		openSearchApi := api.NewMockIOpenSearchApi(ctrl)
+		openSearchApi.EXPECT().
+			Search(gomock.Any(), "products", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, indexName string, query string) (string, error) {
+				assert.Contains(t, query, `"filter":[]`)
+				return resBody, nil
+			})
+
+		testee, err := service.NewProductSearchService(openSearchApi)
+		assert.NoError(t, err)
+
+		_, err = testee.Search(context.Background(), "", 0, 20)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("テナントを解決できていない場合は検索せずにエラーを返すこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		openSearchApi := api.NewMockIOpenSearchApi(ctrl)
+
+		testee, err := service.NewProductSearchService(openSearchApi)
+		assert.NoError(t, err)
+
+		_, err = testee.Search(tenancy.WithUnresolvedTenant(context.Background()), "", 0, 20)
+
+		assert.ErrorIs(t, err, tenancy.ErrTenantRequired)
+	})
+}
@@ -4,17 +4,22 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/rotisserie/eris"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
 	"github.com/t-kuni/cqrs-example/ent"
 	"github.com/t-kuni/cqrs-example/ent/product"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/t-kuni/cqrs-example/domain/service"
+
 // IProductTransferService は RDB上のproductをOpenSearchに同期するサービスのインターフェースです。
 // データ移行やバッチ処理で使用されることを想定しています。
 type IProductTransferService interface {
@@ -25,25 +30,38 @@ type IProductTransferService interface {
 	// TransferProduct は 指定された product を OpenSearch に同期します。
 	// 既に同じproductIdが存在する場合は更新されます。
 	TransferProduct(ctx context.Context, productID uuid.UUID) error
+
+	// DeleteProduct は 指定された product を OpenSearch から削除します。
+	// RDB上のproductを削除した際に、検索用のドキュメントを追従させるために使用します。
+	DeleteProduct(ctx context.Context, productID uuid.UUID) error
 }
 
 // ProductTransferService は IProductTransferService の実装です。
 type ProductTransferService struct {
 	DBConnector   db.IConnector
 	OpenSearchApi api.IOpenSearchApi
+	Logger        system.ILogger
+	Tracer        trace.Tracer
+	Metrics       system.IMetrics
 }
 
 // NewProductTransferService は ProductTransferService の新しいインスタンスを作成します。
-func NewProductTransferService(conn db.IConnector, openSearchApi api.IOpenSearchApi) (IProductTransferService, error) {
+func NewProductTransferService(conn db.IConnector, openSearchApi api.IOpenSearchApi, logger system.ILogger, tp trace.TracerProvider, metrics system.IMetrics) (IProductTransferService, error) {
 	return &ProductTransferService{
 		DBConnector:   conn,
 		OpenSearchApi: openSearchApi,
+		Logger:        logger,
+		Tracer:        tp.Tracer(tracerName),
+		Metrics:       metrics,
 	}, nil
 }
 
 // TransferAllProducts は RDB の全 product を OpenSearch に同期します。
-func (s *ProductTransferService) TransferAllProducts(ctx context.Context) error {
-	client := s.DBConnector.GetEnt()
+func (s *ProductTransferService) TransferAllProducts(ctx context.Context) (err error) {
+	ctx, span := s.Tracer.Start(ctx, "ProductTransferService.TransferAllProducts")
+	defer func() { endSpan(span, err) }()
+
+	client := db.ReadEnt(ctx, s.DBConnector)
 
 	// 全productのIDを取得
 	productIDs, err := client.Product.
@@ -53,7 +71,9 @@ func (s *ProductTransferService) TransferAllProducts(ctx context.Context) error
 		return eris.Wrap(err, "")
 	}
 
-	fmt.Printf("Total products to transfer: %d\n", len(productIDs))
+	span.SetAttributes(attribute.Int("product.count", len(productIDs)))
+	s.Metrics.SetProjectionLag(len(productIDs))
+	s.Logger.SimpleInfoF("Total products to transfer: %d", len(productIDs))
 
 	// 各productIDに対してTransferProductを呼び出す
 	for i, productID := range productIDs {
@@ -61,21 +81,35 @@ func (s *ProductTransferService) TransferAllProducts(ctx context.Context) error
 		if err != nil {
 			return eris.Wrap(err, "")
 		}
+		s.Metrics.SetProjectionLag(len(productIDs) - (i + 1))
 
 		// 進捗表示（10000件ごと）
 		if (i+1)%10000 == 0 {
-			fmt.Printf("Progress: %d/%d products transferred\n", i+1, len(productIDs))
+			s.Logger.SimpleInfoF("Progress: %d/%d products transferred", i+1, len(productIDs))
 		}
 	}
 
-	fmt.Printf("Completed: %d/%d products transferred\n", len(productIDs), len(productIDs))
+	s.Logger.SimpleInfoF("Completed: %d/%d products transferred", len(productIDs), len(productIDs))
 
 	return nil
 }
 
 // TransferProduct は 指定された product を OpenSearch に同期します。
-func (s *ProductTransferService) TransferProduct(ctx context.Context, productID uuid.UUID) error {
-	client := s.DBConnector.GetEnt()
+// DBからの取得と OpenSearch への登録はそれぞれ子スパンとして記録されます。
+func (s *ProductTransferService) TransferProduct(ctx context.Context, productID uuid.UUID) (err error) {
+	ctx, span := s.Tracer.Start(ctx, "ProductTransferService.TransferProduct",
+		trace.WithAttributes(attribute.String("product.id", productID.String())),
+	)
+	defer func() {
+		if err != nil {
+			s.Metrics.IncProjectionFailures()
+		} else {
+			s.Metrics.IncProjectionIndexed()
+		}
+		endSpan(span, err)
+	}()
+
+	client := db.ReadEnt(ctx, s.DBConnector)
 
 	// productを取得（関連エンティティも含む）
 	p, err := client.Product.
@@ -170,3 +204,21 @@ func (s *ProductTransferService) TransferProduct(ctx context.Context, productID
 
 	return nil
 }
+
+// DeleteProduct は 指定された product を OpenSearch から削除します。
+func (s *ProductTransferService) DeleteProduct(ctx context.Context, productID uuid.UUID) error {
+	err := s.OpenSearchApi.DeleteDocument(ctx, "products", productID.String())
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+
+	return nil
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,64 @@
+package tenancy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// ErrTenantRequired は テナントの範囲に制限されたリクエストでテナントが解決できなかった場合のエラーです。
+var ErrTenantRequired = &types.BasicBusinessError{Message: "tenant is required"}
+
+// ErrTenantMismatch は 処理対象のテナント以外のテナントにデータを登録・移動しようとした場合のエラーです。
+var ErrTenantMismatch = &types.BasicBusinessError{Message: "tenant does not match the request"}
+
+type scopeKey struct{}
+
+// scope は リクエストの処理対象のテナントを保持します
+type scope struct {
+	tenantID     uuid.UUID
+	unrestricted bool
+}
+
+// WithTenantID は 処理対象をテナントの範囲に制限したコンテキストを返します。
+// このコンテキストでの product の参照・更新、および OpenSearch の検索は指定したテナントのデータに限定されます。
+func WithTenantID(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{tenantID: tenantID})
+}
+
+// WithUnresolvedTenant は テナントの範囲に制限すべきだがテナントを解決できなかったコンテキストを返します。
+// このコンテキストでの product の参照・更新、および OpenSearch の検索は ErrTenantRequired になります。
+func WithUnresolvedTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{})
+}
+
+// WithoutTenantScope は テナントの範囲の制限を解除したコンテキストを返します。
+// カテゴリに属する product の有無の確認など、テナントを跨いだ整合性の確認や同期処理でのみ使用してください。
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{unrestricted: true})
+}
+
+// FromContext は コンテキストの処理対象のテナントを返します。
+// scoped が false の場合はテナントの範囲に制限されていません（バッチ処理など）。
+// scoped が true で tenantID が uuid.Nil の場合はテナントを解決できていません。
+func FromContext(ctx context.Context) (tenantID uuid.UUID, scoped bool) {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok || s.unrestricted {
+		return uuid.Nil, false
+	}
+	return s.tenantID, true
+}
+
+// Resolve は コンテキストの処理対象のテナントを返します。
+// テナントの範囲に制限されていない場合は ok が false になり、テナントを解決できていない場合は ErrTenantRequired を返します。
+func Resolve(ctx context.Context) (tenantID uuid.UUID, ok bool, err error) {
+	tenantID, scoped := FromContext(ctx)
+	if !scoped {
+		return uuid.Nil, false, nil
+	}
+	if tenantID == uuid.Nil {
+		return uuid.Nil, false, ErrTenantRequired
+	}
+	return tenantID, true, nil
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// AggregateEvent holds the schema definition for the AggregateEvent entity.
+// イベントソーシングの集約ごとのイベントストリームを保持します。
+// (aggregate_id, sequence) の一意制約により、同じ集約への同時追記を検出します（楽観的排他制御）。
+type AggregateEvent struct {
+	ent.Schema
+}
+
+// Annotations of the AggregateEvent.
+func (AggregateEvent) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "events"},
+	}
+}
+
+// Fields of the AggregateEvent.
+func (AggregateEvent) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int64("id"),
+		field.String("aggregate_type"),
+		field.UUID("aggregate_id", uuid.UUID{}),
+		field.Int64("sequence"),
+		field.String("type"),
+		field.JSON("payload", json.RawMessage{}),
+		field.Time("occurred_at"),
+	}
+}
+
+// Edges of the AggregateEvent.
+func (AggregateEvent) Edges() []ent.Edge {
+	return nil
+}
+
+// Indexes of the AggregateEvent.
+func (AggregateEvent) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("aggregate_id", "sequence").Unique(),
+	}
+}
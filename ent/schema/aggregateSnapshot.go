@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+)
+
+// AggregateSnapshot holds the schema definition for the AggregateSnapshot entity.
+// イベントソーシングの集約を復元する際に、全イベントを再生しなくて済むように途中の状態を保持します。
+type AggregateSnapshot struct {
+	ent.Schema
+}
+
+// Fields of the AggregateSnapshot.
+func (AggregateSnapshot) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}),
+		field.String("aggregate_type"),
+		field.Int64("sequence"),
+		field.JSON("state", json.RawMessage{}),
+		field.Time("created_at"),
+	}
+}
+
+// Edges of the AggregateSnapshot.
+func (AggregateSnapshot) Edges() []ent.Edge {
+	return nil
+}
@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+)
+
+// DomainEvent holds the schema definition for the DomainEvent entity.
+// コマンドのトランザクション内で記録されたドメインイベントを保持し、永続購読者が順番に読み出します。
+type DomainEvent struct {
+	ent.Schema
+}
+
+// Fields of the DomainEvent.
+func (DomainEvent) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int64("id"),
+		field.String("name"),
+		field.JSON("payload", json.RawMessage{}),
+		field.Time("occurred_at"),
+	}
+}
+
+// Edges of the DomainEvent.
+func (DomainEvent) Edges() []ent.Edge {
+	return nil
+}
@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+)
+
+// EventSubscriberOffset holds the schema definition for the EventSubscriberOffset entity.
+// 永続購読者ごとに、処理済みのドメインイベントの位置を保持します。
+type EventSubscriberOffset struct {
+	ent.Schema
+}
+
+// Fields of the EventSubscriberOffset.
+func (EventSubscriberOffset) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id"),
+		field.Int64("last_event_id"),
+		field.Time("updated_at"),
+	}
+}
+
+// Edges of the EventSubscriberOffset.
+func (EventSubscriberOffset) Edges() []ent.Edge {
+	return nil
+}
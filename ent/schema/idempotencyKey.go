@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"net/http"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey holds the schema definition for the IdempotencyKey entity.
+// Idempotency-Key ヘッダで送信されたキーごとに、リクエストのハッシュと処理結果のレスポンスを保持します。
+// キーは送信した主体と処理対象のテナントの範囲で一意で、他の主体・テナントのレスポンスは再生しません。
+type IdempotencyKey struct {
+	ent.Schema
+}
+
+// Fields of the IdempotencyKey.
+func (IdempotencyKey) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("principal_id", uuid.UUID{}),
+		// テナントの範囲に制限されていないリクエストの場合は uuid.Nil
+		field.UUID("tenant_id", uuid.UUID{}),
+		field.String("key").MaxLen(255),
+		field.String("request_hash"),
+		// 処理中の場合は 0
+		field.Int("status_code").Default(0),
+		field.JSON("response_header", http.Header{}).Optional(),
+		field.Bytes("response_body").Optional(),
+		field.Time("created_at"),
+		field.Time("expires_at"),
+	}
+}
+
+// Edges of the IdempotencyKey.
+func (IdempotencyKey) Edges() []ent.Edge {
+	return nil
+}
+
+// Indexes of the IdempotencyKey.
+func (IdempotencyKey) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("principal_id", "tenant_id", "key").Unique(),
+	}
+}
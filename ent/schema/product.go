@@ -4,6 +4,7 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 	"github.com/google/uuid"
 	"github.com/t-kuni/cqrs-example/domain/model"
 )
@@ -13,6 +14,13 @@ type Product struct {
 	ent.Schema
 }
 
+// Mixin of the Product.
+func (Product) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		VersionMixin{},
+	}
+}
+
 // Fields of the Product.
 func (Product) Fields() []ent.Field {
 	return []ent.Field{
@@ -42,3 +50,15 @@ func (Product) Edges() []ent.Edge {
 	}
 }
 
+// Indexes of the Product.
+// product のクエリはテナントスコープにより常に tenant_id で絞り込まれるため、tenant_id を先頭にした複合インデックスにしています
+// properties の size / color の生成列とそのインデックスは ent で表現できないため、マイグレーションファイルで定義しています
+func (Product) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("tenant_id", "category_id"),
+		index.Fields("tenant_id", "listed_at"),
+		index.Fields("tenant_id", "price"),
+		// テナントをまたいでカテゴリで絞り込むクエリ（カテゴリの削除時の確認など）のため
+		index.Fields("category_id"),
+	}
+}
@@ -2,8 +2,10 @@ package schema
 
 import (
 	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 	"github.com/google/uuid"
 )
 
@@ -12,6 +14,13 @@ type Tenant struct {
 	ent.Schema
 }
 
+// Mixin of the Tenant.
+func (Tenant) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		VersionMixin{},
+	}
+}
+
 // Fields of the Tenant.
 func (Tenant) Fields() []ent.Field {
 	return []ent.Field{
@@ -30,5 +39,16 @@ func (Tenant) Edges() []ent.Edge {
 			Required().
 			Field("owner_id"),
 		edge.To("products", Product.Type),
+		edge.To("memberships", Membership.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+	}
+}
+
+// Indexes of the Tenant.
+func (Tenant) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("owner_id"),
+		// 一覧は name, id の順に並べるため
+		index.Fields("name"),
 	}
 }
@@ -0,0 +1,20 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+)
+
+// VersionMixin は 楽観的排他制御に使用する version フィールドを追加します。
+// 更新時の version のチェックとインクリメントは infrastructure/db で登録するフックが行います。
+type VersionMixin struct {
+	mixin.Schema
+}
+
+// Fields of the VersionMixin.
+func (VersionMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int64("version").Default(1),
+	}
+}
@@ -1,33 +1,198 @@
 package errors
 
 import (
+	"encoding/json"
 	"github.com/go-openapi/errors"
+	"github.com/rotisserie/eris"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"github.com/t-kuni/cqrs-example/restapi/models"
+	"github.com/t-kuni/cqrs-example/util"
 	"net/http"
+	"strings"
 )
 
 // NewCustomServeError はカスタムエラーハンドラを生成する関数です
+// エラーはエラーコードのカタログ（types.Code）に対応する HTTP ステータスと swagger の error 定義の JSON で返します
 func NewCustomServeError(logger system.ILogger) func(w http.ResponseWriter, r *http.Request, err error) {
 	return func(rw http.ResponseWriter, r *http.Request, err error) {
-		switch err.(type) {
-		case *errors.CompositeError,
-			*errors.MethodNotAllowedError,
-			errors.Error:
-			// go-openapi管轄のエラーは標準エラーハンドラであるerrors.ServeErrorに移譲する
+		// エラーコードを持つアプリケーションのエラー
+		if codedErr, ok := asCodedError(err); ok {
+			if logger != nil {
+				logger.WarnWithError(r, err, paramsOf(codedErr))
+			}
+			writeCodedError(rw, r, codedErr)
+			return
+		}
+
+		switch e := err.(type) {
+		case *errors.CompositeError:
+			// go-openapi のバリデーションエラーは項目ごとのエラーを details に出力する
+			if logger != nil {
+				logger.WarnWithError(r, err, nil)
+			}
+			writeError(rw, r, compositeStatus(e), types.CodeValidation, e.Error(), fieldErrorsOf(e))
+		case *errors.MethodNotAllowedError:
 			if logger != nil {
 				logger.WarnWithError(r, err, nil)
 			}
-			errors.ServeError(rw, r, err)
+			rw.Header().Add("Allow", strings.Join(e.Allowed, ","))
+			writeError(rw, r, int(e.Code()), types.CodeMethodNotAllowed, e.Error(), nil)
+		case errors.Error:
+			// その他の go-openapi管轄のエラーは go-openapi が決めたステータスで返す
+			if logger != nil {
+				logger.WarnWithError(r, err, nil)
+			}
+			status := asHTTPCode(int(e.Code()))
+			writeError(rw, r, status, types.CodeFromHTTPStatus(status), e.Error(), fieldErrorsOf(e))
 		case nil:
 			if logger != nil {
 				logger.Panic(r, "Unknown Error", nil)
 			}
-			rw.WriteHeader(http.StatusInternalServerError)
+			WriteInternalError(rw, r)
 		default:
 			if logger != nil {
 				logger.PanicV2(r, err.Error(), nil)
 			}
-			rw.WriteHeader(http.StatusInternalServerError)
+			WriteInternalError(rw, r)
+		}
+	}
+}
+
+// asCodedError はエラーの原因がエラーコードを持つエラーの場合にそれを取り出します
+func asCodedError(err error) (types.ICodedError, bool) {
+	var codedErr types.ICodedError
+	if err == nil || !eris.As(err, &codedErr) {
+		return nil, false
+	}
+	return codedErr, true
+}
+
+// asConflictError はエラーの原因が ConflictError の場合にそれを取り出します
+func asConflictError(err error) (*types.ConflictError, bool) {
+	var conflictErr *types.ConflictError
+	if err == nil || !eris.As(err, &conflictErr) {
+		return nil, false
+	}
+	return conflictErr, true
+}
+
+// writeCodedError はエラーコードを持つエラーをエラーコードに対応するステータスのレスポンスとして出力します
+// 楽観的排他制御の競合は最新の version を含む ConflictError のモデルで出力します
+func writeCodedError(rw http.ResponseWriter, r *http.Request, codedErr types.ICodedError) {
+	if conflictErr, ok := asConflictError(codedErr); ok {
+		writeConflictError(rw, r, conflictErr)
+		return
+	}
+
+	var details []types.FieldError
+	var validationErr *types.ValidationError
+	if eris.As(codedErr, &validationErr) {
+		details = validationErr.Details
+	}
+
+	code := codedErr.ErrorCode()
+	writeError(rw, r, code.HTTPStatus(), code, codedErr.Error(), details)
+}
+
+// writeConflictError は ConflictError を 409 のレスポンスとして出力します
+func writeConflictError(rw http.ResponseWriter, r *http.Request, conflictErr *types.ConflictError) {
+	if AcceptsProblemJSON(r) {
+		writeProblem(rw, r, http.StatusConflict, types.CodeConflict, conflictErr.Message, nil, conflictErr.CurrentVersion)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusConflict)
+	json.NewEncoder(rw).Encode(&models.ConflictError{
+		ErrorCode:      string(types.CodeConflict),
+		Message:        util.Ptr(conflictErr.Message),
+		CurrentVersion: conflictErr.CurrentVersion,
+	})
+}
+
+// WriteInternalError は想定外のエラーを 500 のレスポンスとして出力します（エラーの内容はレスポンスに含めません）
+// パニックから復帰したミドルウェアからも使用します
+func WriteInternalError(rw http.ResponseWriter, r *http.Request) {
+	writeError(rw, r, http.StatusInternalServerError, types.CodeInternal, "internal server error", nil)
+}
+
+// writeError はエラーをレスポンスとして出力します
+// Accept ヘッダで application/problem+json が要求されている場合は RFC 7807 の problem details、それ以外は swagger の error 定義の JSON です
+func writeError(rw http.ResponseWriter, r *http.Request, status int, code types.Code, message string, details []types.FieldError) {
+	if AcceptsProblemJSON(r) {
+		writeProblem(rw, r, status, code, message, details, 0)
+		return
+	}
+
+	payload := newErrorModel(code, message, details)
+	payload.Code = int64(status)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(payload)
+}
+
+func newErrorModel(code types.Code, message string, details []types.FieldError) *models.Error {
+	payload := &models.Error{
+		Code:      int64(code.HTTPStatus()),
+		ErrorCode: string(code),
+		Message:   util.Ptr(message),
+	}
+	for _, d := range details {
+		payload.Details = append(payload.Details, &models.ErrorDetail{
+			Field:   d.Field,
+			Message: util.Ptr(d.Message),
+		})
+	}
+	return payload
+}
+
+// fieldErrorsOf は go-openapi のエラーから項目ごとのエラーを取り出します
+func fieldErrorsOf(err error) []types.FieldError {
+	switch e := err.(type) {
+	case *errors.CompositeError:
+		var details []types.FieldError
+		for _, inner := range e.Errors {
+			details = append(details, fieldErrorsOf(inner)...)
+		}
+		return details
+	case *errors.Validation:
+		return []types.FieldError{{Field: e.Name, Message: e.Error()}}
+	case *errors.ParseError:
+		return []types.FieldError{{Field: e.Name, Message: e.Error()}}
+	default:
+		return nil
+	}
+}
+
+// compositeStatus は go-openapi の errors.ServeError と同じく、エラーが1つの場合はそのステータスを返します
+func compositeStatus(e *errors.CompositeError) int {
+	if len(e.Errors) == 1 {
+		if inner, ok := e.Errors[0].(errors.Error); ok {
+			return asHTTPCode(int(inner.Code()))
 		}
 	}
+	return asHTTPCode(int(e.Code()))
+}
+
+// asHTTPCode は go-openapi のエラーコードを HTTP ステータスに変換します
+// go-openapi のバリデーションエラーは 600 番台のコードを持つため 422 として扱います
+func asHTTPCode(code int) int {
+	if code >= 600 {
+		return http.StatusUnprocessableEntity
+	}
+	return code
+}
+
+func paramsOf(codedErr types.ICodedError) map[string]interface{} {
+	switch e := codedErr.(type) {
+	case *types.BasicBusinessError:
+		return e.Params
+	case *types.ForbiddenError:
+		return e.Params
+	case *types.NotFoundError:
+		return e.Params
+	default:
+		return nil
+	}
 }
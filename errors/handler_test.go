@@ -0,0 +1,292 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openapiErrors "github.com/go-openapi/errors"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+	"go.uber.org/mock/gomock"
+)
+
+type errorBody struct {
+	Code      int64  `json:"code"`
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+	Details   []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"details"`
+}
+
+func decodeErrorBody(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	var body errorBody
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func TestNewCustomServeError_ConflictErrorの場合ステータスコード409と最新のバージョンを返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodPut, "/products/b3c9e1a4-0000-4000-8000-000000000001", nil)
+	rec := httptest.NewRecorder()
+	err := eris.Wrap(types.NewConflictError("product was modified by another request", 3), "")
+
+	//
+	// Execute
+	//
+	serveError(rec, req, err)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"errorCode":"CONFLICT","message":"product was modified by another request","currentVersion":3}`, rec.Body.String())
+}
+
+func TestNewErrorResponder_ConflictErrorの場合ステータスコード409を返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	rec := httptest.NewRecorder()
+	err := eris.Wrap(types.NewConflictError("tenant was modified by another request", 0), "")
+
+	//
+	// Execute
+	//
+	errors.NewErrorResponder(nil, httptest.NewRequest(http.MethodPost, "/products", nil), err).WriteResponse(rec, nil)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"errorCode":"CONFLICT","message":"tenant was modified by another request"}`, rec.Body.String())
+}
+
+func TestNewCustomServeError_エラーコードを持つエラーの場合カタログのステータスコードで返すこと(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		status    int
+		errorCode string
+	}{
+		{"NotFoundError", types.NewNotFoundError("product not found", nil), http.StatusNotFound, "NOT_FOUND"},
+		{"ForbiddenError", types.NewForbiddenError("permission denied", nil), http.StatusForbidden, "FORBIDDEN"},
+		{"BasicBusinessError", types.NewBasicBusinessError("tenant not found", nil), http.StatusBadRequest, "BUSINESS_RULE_VIOLATION"},
+		{"ValidationError", types.NewValidationError("validation failed", nil), http.StatusBadRequest, "VALIDATION_FAILED"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			serveError := errors.NewCustomServeError(nil)
+			req := httptest.NewRequest(http.MethodPost, "/products", nil)
+			rec := httptest.NewRecorder()
+
+			serveError(rec, req, eris.Wrap(c.err, ""))
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			body := decodeErrorBody(t, rec)
+			assert.Equal(t, int64(c.status), body.Code)
+			assert.Equal(t, c.errorCode, body.ErrorCode)
+			assert.Equal(t, c.err.Error(), body.Message)
+		})
+	}
+}
+
+func TestNewCustomServeError_ValidationErrorの場合項目ごとのエラーを返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodPut, "/products/b3c9e1a4-0000-4000-8000-000000000001", nil)
+	rec := httptest.NewRecorder()
+	err := eris.Wrap(types.NewValidationError("validation failed", []types.FieldError{
+		{Field: "Version", Message: "failed on the 'gte' rule"},
+	}), "")
+
+	//
+	// Execute
+	//
+	serveError(rec, req, err)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorBody(t, rec)
+	assert.Equal(t, "VALIDATION_FAILED", body.ErrorCode)
+	assert.Len(t, body.Details, 1)
+	assert.Equal(t, "Version", body.Details[0].Field)
+	assert.Equal(t, "failed on the 'gte' rule", body.Details[0].Message)
+}
+
+func TestNewCustomServeError_go_openapiのバリデーションエラーの場合422と項目ごとのエラーを返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodPost, "/products", nil)
+	rec := httptest.NewRecorder()
+	err := openapiErrors.CompositeValidationError(
+		openapiErrors.Required("name", "body", nil),
+		openapiErrors.ExceedsMinimum("price", "body", 0, false, -1),
+	)
+
+	//
+	// Execute
+	//
+	serveError(rec, req, err)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	body := decodeErrorBody(t, rec)
+	assert.Equal(t, int64(http.StatusUnprocessableEntity), body.Code)
+	assert.Equal(t, "VALIDATION_FAILED", body.ErrorCode)
+	assert.Len(t, body.Details, 2)
+	assert.Equal(t, "name", body.Details[0].Field)
+	assert.Equal(t, "price", body.Details[1].Field)
+}
+
+func TestNewCustomServeError_MethodNotAllowedErrorの場合405とAllowヘッダを返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodPatch, "/products", nil)
+	rec := httptest.NewRecorder()
+	err := openapiErrors.MethodNotAllowed(http.MethodPatch, []string{http.MethodGet, http.MethodPost})
+
+	//
+	// Execute
+	//
+	serveError(rec, req, err)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET,POST", rec.Header().Get("Allow"))
+	assert.Equal(t, "METHOD_NOT_ALLOWED", decodeErrorBody(t, rec).ErrorCode)
+}
+
+func TestNewCustomServeError_go_openapiのエラーの場合そのステータスコードで返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rec := httptest.NewRecorder()
+	err := openapiErrors.NotFound("path %s was not found", "/unknown")
+
+	//
+	// Execute
+	//
+	serveError(rec, req, err)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	body := decodeErrorBody(t, rec)
+	assert.Equal(t, "NOT_FOUND", body.ErrorCode)
+	assert.Equal(t, "path /unknown was not found", body.Message)
+}
+
+func TestNewCustomServeError_想定外のエラーの場合500を返しエラーの内容を含めないこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	rec := httptest.NewRecorder()
+
+	//
+	// Execute
+	//
+	serveError(rec, req, fmt.Errorf("dial tcp: connection refused"))
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	body := decodeErrorBody(t, rec)
+	assert.Equal(t, "INTERNAL_ERROR", body.ErrorCode)
+	assert.Equal(t, "internal server error", body.Message)
+}
+
+func TestNewErrorResponder_エラーコードを持つエラーの場合カタログのステータスコードを返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	rec := httptest.NewRecorder()
+	err := eris.Wrap(types.NewForbiddenError("permission denied", nil), "")
+
+	//
+	// Execute
+	//
+	errors.NewErrorResponder(nil, httptest.NewRequest(http.MethodPost, "/products", nil), err).WriteResponse(rec, nil)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := decodeErrorBody(t, rec)
+	assert.Equal(t, "FORBIDDEN", body.ErrorCode)
+	assert.Equal(t, "permission denied", body.Message)
+}
+
+func TestNewErrorResponder_想定外のエラーの場合ログを出力して500を返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/products", nil)
+	err := eris.New("unexpected")
+	logger := system.NewMockILogger(gomock.NewController(t))
+	logger.EXPECT().Error(req, err, nil)
+
+	//
+	// Execute
+	//
+	errors.NewErrorResponder(logger, req, err).WriteResponse(rec, nil)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "INTERNAL_ERROR", decodeErrorBody(t, rec).ErrorCode)
+}
+
+func TestNewErrorResponder_problemJSONが要求された場合problem_detailsで返すこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/products/1", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	err := eris.Wrap(types.NewConflictError("product was modified by another request", 3), "")
+
+	//
+	// Execute
+	//
+	errors.NewErrorResponder(nil, req, err).WriteResponse(rec, nil)
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"/problems/conflict","title":"Conflict","status":409,"detail":"product was modified by another request","instance":"/products/1","errorCode":"CONFLICT","currentVersion":3}`, rec.Body.String())
+}
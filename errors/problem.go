@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// ProblemJSONMediaType は RFC 7807 の problem details のメディアタイプです
+const ProblemJSONMediaType = "application/problem+json"
+
+// ProblemDetails は RFC 7807 の problem details です
+// 拡張メンバとしてリクエストID・エラーコード・項目ごとのエラー・最新の version を出力します
+type ProblemDetails struct {
+	Type           string              `json:"type"`
+	Title          string              `json:"title"`
+	Status         int                 `json:"status"`
+	Detail         string              `json:"detail,omitempty"`
+	Instance       string              `json:"instance,omitempty"`
+	RequestID      string              `json:"requestId,omitempty"`
+	ErrorCode      string              `json:"errorCode,omitempty"`
+	Errors         []ProblemFieldError `json:"errors,omitempty"`
+	CurrentVersion int64               `json:"currentVersion,omitempty"`
+}
+
+// ProblemFieldError は problem details の項目ごとのエラーです
+type ProblemFieldError struct {
+	Field  string `json:"field,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// AcceptsProblemJSON は Accept ヘッダで application/problem+json が application/json 以上の優先度で要求されているかを返します
+// Accept ヘッダが無い場合や application/problem+json を含まない場合は false です
+func AcceptsProblemJSON(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	problemQ, jsonQ := -1.0, -1.0
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+			switch mediaType {
+			case ProblemJSONMediaType:
+				problemQ = max(problemQ, q)
+			case "application/json":
+				jsonQ = max(jsonQ, q)
+			}
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+// problemType は エラーコードに対応する problem の type（相対 URI）を返します
+func problemType(code types.Code) string {
+	return "/problems/" + strings.ToLower(strings.ReplaceAll(string(code), "_", "-"))
+}
+
+// writeProblem は エラーを problem details のレスポンスとして出力します
+func writeProblem(rw http.ResponseWriter, r *http.Request, status int, code types.Code, detail string, details []types.FieldError, currentVersion int64) {
+	problem := &ProblemDetails{
+		Type:           problemType(code),
+		Title:          http.StatusText(status),
+		Status:         status,
+		Detail:         detail,
+		Instance:       r.URL.RequestURI(),
+		RequestID:      correlation.RequestIDFrom(r.Context()),
+		ErrorCode:      string(code),
+		CurrentVersion: currentVersion,
+	}
+	for _, d := range details {
+		problem.Errors = append(problem.Errors, ProblemFieldError{
+			Field:  d.Field,
+			Detail: d.Message,
+		})
+	}
+
+	rw.Header().Set("Content-Type", ProblemJSONMediaType)
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(problem)
+}
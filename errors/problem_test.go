@@ -0,0 +1,176 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openapiErrors "github.com/go-openapi/errors"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+func TestAcceptsProblemJSON(t *testing.T) {
+	cases := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"*/*", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json", true},
+		{"application/json;q=0.9, application/problem+json", true},
+		{"application/json, application/problem+json;q=0.5", false},
+		{"application/problem+json;q=0", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+			assert.Equal(t, c.expected, errors.AcceptsProblemJSON(req))
+		})
+	}
+}
+
+func TestNewCustomServeError_problem_jsonが要求された場合(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		status    int
+		errorCode string
+		detail    string
+		fields    []string
+	}{
+		{
+			name:      "CompositeError",
+			err:       openapiErrors.CompositeValidationError(openapiErrors.Required("name", "body", nil), openapiErrors.Required("price", "body", nil)),
+			status:    http.StatusUnprocessableEntity,
+			errorCode: "VALIDATION_FAILED",
+			detail:    "validation failure list:\nname in body is required\nprice in body is required",
+			fields:    []string{"name", "price"},
+		},
+		{
+			name:      "MethodNotAllowedError",
+			err:       openapiErrors.MethodNotAllowed(http.MethodPatch, []string{http.MethodGet}),
+			status:    http.StatusMethodNotAllowed,
+			errorCode: "METHOD_NOT_ALLOWED",
+			detail:    "method PATCH is not allowed, but [GET] are",
+		},
+		{
+			name:      "errors.Error",
+			err:       openapiErrors.NotFound("path %s was not found", "/products"),
+			status:    http.StatusNotFound,
+			errorCode: "NOT_FOUND",
+			detail:    "path /products was not found",
+		},
+		{
+			name:      "errors.Error（Unauthenticated）",
+			err:       openapiErrors.Unauthenticated("Bearer"),
+			status:    http.StatusUnauthorized,
+			errorCode: "UNAUTHORIZED",
+			detail:    "unauthenticated for Bearer",
+		},
+		{
+			name:      "errors.Error（ParseError）",
+			err:       openapiErrors.NewParseError("page", "query", "abc", fmt.Errorf("invalid syntax")),
+			status:    http.StatusBadRequest,
+			errorCode: "VALIDATION_FAILED",
+			detail:    `parsing page query from "abc" failed, because invalid syntax`,
+			fields:    []string{"page"},
+		},
+		{
+			name:      "nil",
+			err:       nil,
+			status:    http.StatusInternalServerError,
+			errorCode: "INTERNAL_ERROR",
+			detail:    "internal server error",
+		},
+		{
+			name:      "想定外のエラー",
+			err:       fmt.Errorf("dial tcp: connection refused"),
+			status:    http.StatusInternalServerError,
+			errorCode: "INTERNAL_ERROR",
+			detail:    "internal server error",
+		},
+		{
+			name:      "エラーコードを持つエラー",
+			err:       eris.Wrap(types.NewNotFoundError("product not found", nil), ""),
+			status:    http.StatusNotFound,
+			errorCode: "NOT_FOUND",
+			detail:    "product not found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			serveError := errors.NewCustomServeError(nil)
+			req := httptest.NewRequest(http.MethodPost, "/products?page=abc", nil)
+			req.Header.Set("Accept", "application/problem+json")
+			req = req.WithContext(correlation.WithRequestID(req.Context(), "req-1"))
+			rec := httptest.NewRecorder()
+
+			serveError(rec, req, c.err)
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, errors.ProblemJSONMediaType, rec.Header().Get("Content-Type"))
+
+			var problem errors.ProblemDetails
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, c.status, problem.Status)
+			assert.Equal(t, http.StatusText(c.status), problem.Title)
+			assert.Equal(t, c.errorCode, problem.ErrorCode)
+			assert.Equal(t, c.detail, problem.Detail)
+			assert.Equal(t, "/products?page=abc", problem.Instance)
+			assert.Equal(t, "req-1", problem.RequestID)
+			assert.NotEmpty(t, problem.Type)
+
+			fields := make([]string, 0, len(problem.Errors))
+			for _, e := range problem.Errors {
+				fields = append(fields, e.Field)
+			}
+			if c.fields == nil {
+				assert.Empty(t, fields)
+			} else {
+				assert.Equal(t, c.fields, fields)
+			}
+		})
+	}
+}
+
+func TestNewCustomServeError_problem_jsonが要求された場合ConflictErrorは最新のバージョンを含むこと(t *testing.T) {
+	//
+	// Prepare
+	//
+	serveError := errors.NewCustomServeError(nil)
+	req := httptest.NewRequest(http.MethodPut, "/products/b3c9e1a4-0000-4000-8000-000000000001", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	rec := httptest.NewRecorder()
+
+	//
+	// Execute
+	//
+	serveError(rec, req, eris.Wrap(types.NewConflictError("product was modified by another request", 3), ""))
+
+	//
+	// Assert
+	//
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{
+		"type": "/problems/conflict",
+		"title": "Conflict",
+		"status": 409,
+		"detail": "product was modified by another request",
+		"instance": "/products/b3c9e1a4-0000-4000-8000-000000000001",
+		"errorCode": "CONFLICT",
+		"currentVersion": 3
+	}`, rec.Body.String())
+}
@@ -1,12 +1,29 @@
 package errors
 
 import (
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"net/http"
 )
 
 // NewErrorResponder はエラーをResponderに変換するヘルパー関数です
-func NewErrorResponder(err error) middleware.Responder {
-	// エラーを直接返す
-	// グローバルエラーハンドラがこのエラーを処理します
-	return middleware.Error(500, err)
+// go-openapi は Responder を返すとグローバルエラーハンドラを経由しないため、
+// エラーコードを持つエラーは NewCustomServeError と同じくエラーコードに対応するステータスのレスポンスにします
+// r にはハンドラのパラメータの HTTPRequest を渡してください。Accept ヘッダで application/problem+json が要求されている場合は problem details で返します
+// 想定外のエラーは NewCustomServeError と同じく logger にスタックトレース付きで出力してから 500 を返します
+func NewErrorResponder(logger system.ILogger, r *http.Request, err error) middleware.Responder {
+	if codedErr, ok := asCodedError(err); ok {
+		return middleware.ResponderFunc(func(rw http.ResponseWriter, _ runtime.Producer) {
+			writeCodedError(rw, r, codedErr)
+		})
+	}
+
+	// 想定外のエラーは 500 を返す
+	return middleware.ResponderFunc(func(rw http.ResponseWriter, _ runtime.Producer) {
+		if logger != nil {
+			logger.Error(r, err, nil)
+		}
+		WriteInternalError(rw, r)
+	})
 }
@@ -18,3 +18,7 @@ func NewBasicBusinessError(message string, params map[string]interface{}) error
 func (e BasicBusinessError) Error() string {
 	return e.Message
 }
+
+func (e BasicBusinessError) ErrorCode() Code {
+	return CodeBusinessRule
+}
@@ -0,0 +1,74 @@
+package types
+
+import "net/http"
+
+// Code は APIのエラーレスポンスの errorCode に出力するエラーコードです
+// swagger.yml の error 定義の errorCode と対応します
+type Code string
+
+const (
+	// CodeValidation は 入力値が不正な場合のエラーコードです
+	CodeValidation Code = "VALIDATION_FAILED"
+	// CodeBusinessRule は 業務上のルールに違反する場合のエラーコードです
+	CodeBusinessRule Code = "BUSINESS_RULE_VIOLATION"
+	// CodeUnauthorized は 認証されていない場合のエラーコードです
+	CodeUnauthorized Code = "UNAUTHORIZED"
+	// CodeForbidden は 操作の権限が無い場合のエラーコードです
+	CodeForbidden Code = "FORBIDDEN"
+	// CodeNotFound は 対象のリソースが存在しない場合のエラーコードです
+	CodeNotFound Code = "NOT_FOUND"
+	// CodeMethodNotAllowed は 対象のリソースが HTTP メソッドに対応していない場合のエラーコードです
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	// CodeConflict は 他のリソースや他のリクエストによる更新と競合する場合のエラーコードです
+	CodeConflict Code = "CONFLICT"
+	// CodeInternal は 想定外のエラーのエラーコードです
+	CodeInternal Code = "INTERNAL_ERROR"
+)
+
+// catalog は エラーコードごとの HTTP ステータスです
+var catalog = map[Code]int{
+	CodeValidation:       http.StatusBadRequest,
+	CodeBusinessRule:     http.StatusBadRequest,
+	CodeUnauthorized:     http.StatusUnauthorized,
+	CodeForbidden:        http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeMethodNotAllowed: http.StatusMethodNotAllowed,
+	CodeConflict:         http.StatusConflict,
+	CodeInternal:         http.StatusInternalServerError,
+}
+
+// HTTPStatus は エラーコードに対応する HTTP ステータスを返します
+func (c Code) HTTPStatus() int {
+	if status, ok := catalog[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFromHTTPStatus は HTTP ステータスに対応するエラーコードを返します
+// go-openapi のエラーなど、エラーコードを持たないエラーのレスポンスに使用します
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	default:
+		return CodeInternal
+	}
+}
+
+// ICodedError は エラーコードを持つエラーです
+// NewCustomServeError と NewErrorResponder はエラーコードに対応する HTTP ステータスでレスポンスを返します
+type ICodedError interface {
+	error
+	ErrorCode() Code
+}
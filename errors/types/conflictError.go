@@ -0,0 +1,25 @@
+package types
+
+// ConflictError は 楽観的排他制御により、他のリクエストによる更新と競合した場合のエラーです
+type ConflictError struct {
+	// Message for end user and logging
+	Message string
+
+	// CurrentVersion is the latest version of the entity (0 if unknown)
+	CurrentVersion int64
+}
+
+func NewConflictError(message string, currentVersion int64) error {
+	return &ConflictError{
+		Message:        message,
+		CurrentVersion: currentVersion,
+	}
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+func (e ConflictError) ErrorCode() Code {
+	return CodeConflict
+}
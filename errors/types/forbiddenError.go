@@ -0,0 +1,25 @@
+package types
+
+// ForbiddenError は 認証済みの主体に操作の権限が無い場合のエラーです
+type ForbiddenError struct {
+	// Message for end user and logging
+	Message string
+
+	// Params is outputted to log
+	Params map[string]interface{}
+}
+
+func NewForbiddenError(message string, params map[string]interface{}) error {
+	return &ForbiddenError{
+		Message: message,
+		Params:  params,
+	}
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+func (e ForbiddenError) ErrorCode() Code {
+	return CodeForbidden
+}
@@ -0,0 +1,25 @@
+package types
+
+// NotFoundError は 操作の対象のリソースが存在しない場合のエラーです
+type NotFoundError struct {
+	// Message for end user and logging
+	Message string
+
+	// Params is outputted to log
+	Params map[string]interface{}
+}
+
+func NewNotFoundError(message string, params map[string]interface{}) error {
+	return &NotFoundError{
+		Message: message,
+		Params:  params,
+	}
+}
+
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e NotFoundError) ErrorCode() Code {
+	return CodeNotFound
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError は 入力値の項目ごとのエラーです
+type FieldError struct {
+	// Field is the name of the invalid field
+	Field string
+
+	// Message for end user
+	Message string
+}
+
+// ValidationError は 入力値が不正な場合のエラーです
+type ValidationError struct {
+	// Message for end user and logging
+	Message string
+
+	// Details is the list of invalid fields
+	Details []FieldError
+
+	// Cause is the original error (e.g. validator.ValidationErrors)
+	Cause error
+}
+
+func NewValidationError(message string, details []FieldError) error {
+	return &ValidationError{
+		Message: message,
+		Details: details,
+	}
+}
+
+// NewValidationErrorFromValidator は go-playground/validator のエラーを ValidationError に変換します
+// validator.ValidationErrors 以外のエラーはそのまま返します
+func NewValidationErrorFromValidator(err error) error {
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return err
+	}
+
+	details := make([]FieldError, 0, len(vErrs))
+	for _, vErr := range vErrs {
+		details = append(details, FieldError{
+			Field:   vErr.Field(),
+			Message: "failed on the '" + vErr.Tag() + "' rule",
+		})
+	}
+
+	return &ValidationError{
+		Message: "validation failed",
+		Details: details,
+		Cause:   err,
+	}
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+func (e ValidationError) ErrorCode() Code {
+	return CodeValidation
+}
@@ -2,41 +2,54 @@ package api
 
 import (
 	"context"
-	"os"
+	"encoding/json"
+	"io"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2"
 	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/t-kuni/cqrs-example/infrastructure/api"
+
 // OpenSearchApi は IOpenSearchApi インターフェースの実装です。
 type OpenSearchApi struct {
-	client *opensearch.Client
+	client  *opensearch.Client
+	tracer  trace.Tracer
+	metrics system.IMetrics
 }
 
 // NewOpenSearchApi は OpenSearchApi の新しいインスタンスを作成します。
-// 環境変数 OPENSEARCH_ORIGIN から接続先を取得します。
-func NewOpenSearchApi() (api.IOpenSearchApi, error) {
-	origin := os.Getenv("OPENSEARCH_ORIGIN")
-	if origin == "" {
-		return nil, eris.New("OPENSEARCH_ORIGIN environment variable is not set")
-	}
-
+// 接続先は設定の OpenSearch.Origin です。
+// 各操作は OpenTelemetry のスパンとして記録し、処理時間とエラーの件数をメトリクスに記録します。
+func NewOpenSearchApi(cfg *config.Config, tp trace.TracerProvider, metrics system.IMetrics) (api.IOpenSearchApi, error) {
 	client, err := opensearch.NewClient(opensearch.Config{
-		Addresses: []string{origin},
+		Addresses: []string{cfg.OpenSearch.Origin},
 	})
 	if err != nil {
 		return nil, eris.Wrap(err, "")
 	}
 
 	return &OpenSearchApi{
-		client: client,
+		client:  client,
+		tracer:  tp.Tracer(tracerName),
+		metrics: metrics,
 	}, nil
 }
 
 // IndexDocument は OpenSearch にドキュメントを登録または更新します。
-func (o *OpenSearchApi) IndexDocument(ctx context.Context, indexName string, documentID string, document string) error {
+func (o *OpenSearchApi) IndexDocument(ctx context.Context, indexName string, documentID string, document string) (err error) {
+	ctx, done := o.observe(ctx, "index", indexName, attribute.String("opensearch.document.id", documentID))
+	defer func() { done(err) }()
+
 	res, err := o.client.Index(
 		indexName,
 		strings.NewReader(document),
@@ -54,3 +67,137 @@ func (o *OpenSearchApi) IndexDocument(ctx context.Context, indexName string, doc
 
 	return nil
 }
+
+// DeleteDocument は OpenSearch からドキュメントを削除します。
+func (o *OpenSearchApi) DeleteDocument(ctx context.Context, indexName string, documentID string) (err error) {
+	ctx, done := o.observe(ctx, "delete", indexName, attribute.String("opensearch.document.id", documentID))
+	defer func() { done(err) }()
+
+	res, err := o.client.Delete(
+		indexName,
+		documentID,
+		o.client.Delete.WithContext(ctx),
+	)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer res.Body.Close()
+
+	// 削除済みの場合は目的を達成しているのでエラーとしない
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if res.IsError() {
+		return eris.Errorf("failed to delete document: %s", res.Status())
+	}
+
+	return nil
+}
+
+// Search は OpenSearch でドキュメントを検索します。
+func (o *OpenSearchApi) Search(ctx context.Context, indexName string, query string) (_ string, err error) {
+	ctx, done := o.observe(ctx, "search", indexName)
+	defer func() { done(err) }()
+
+	res, err := o.client.Search(
+		o.client.Search.WithIndex(indexName),
+		o.client.Search.WithBody(strings.NewReader(query)),
+		o.client.Search.WithContext(ctx),
+	)
+	if err != nil {
+		return "", eris.Wrap(err, "")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", eris.Errorf("failed to search documents: %s", res.Status())
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", eris.Wrap(err, "")
+	}
+
+	return string(body), nil
+}
+
+// ClusterHealth は OpenSearch クラスタのヘルスステータス（green / yellow / red）を返します。
+func (o *OpenSearchApi) ClusterHealth(ctx context.Context) (_ string, err error) {
+	ctx, done := o.observe(ctx, "cluster_health", "")
+	defer func() { done(err) }()
+
+	res, err := o.client.Cluster.Health(
+		o.client.Cluster.Health.WithContext(ctx),
+	)
+	if err != nil {
+		return "", eris.Wrap(err, "")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", eris.Errorf("failed to get cluster health: %s", res.Status())
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return "", eris.Wrap(err, "")
+	}
+
+	return health.Status, nil
+}
+
+// IndexExists は インデックスが存在するかどうかを返します。
+func (o *OpenSearchApi) IndexExists(ctx context.Context, indexName string) (_ bool, err error) {
+	ctx, done := o.observe(ctx, "index_exists", indexName)
+	defer func() { done(err) }()
+
+	res, err := o.client.Indices.Exists(
+		[]string{indexName},
+		o.client.Indices.Exists.WithContext(ctx),
+	)
+	if err != nil {
+		return false, eris.Wrap(err, "")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if res.IsError() {
+		return false, eris.Errorf("failed to check index existence: %s", res.Status())
+	}
+
+	return true, nil
+}
+
+// observe は OpenSearch の操作のスパンを開始します
+// 返却した関数を操作の終了時に呼び出すと、スパンを終了して処理時間とエラーをメトリクスに記録します
+func (o *OpenSearchApi) observe(ctx context.Context, operation string, indexName string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	attrs = append(attrs,
+		attribute.String("db.system.name", "opensearch"),
+		attribute.String("db.operation.name", operation),
+	)
+	spanName := "opensearch " + operation
+	if indexName != "" {
+		attrs = append(attrs, attribute.String("db.collection.name", indexName))
+		spanName += " " + indexName
+	}
+	ctx, span := o.tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		o.metrics.ObserveOpenSearchRequest(operation, time.Since(start), err)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
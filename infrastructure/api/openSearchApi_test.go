@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/infrastructure/api"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenSearchApi_TracingAndMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	registry := prometheus.NewRegistry()
+	metrics, err := system.NewMetrics(registry)
+	assert.NoError(t, err)
+	testee, err := api.NewOpenSearchApi(&config.Config{OpenSearch: config.OpenSearch{Origin: server.URL}}, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), metrics)
+	assert.NoError(t, err)
+
+	t.Run("操作ごとにスパンと処理時間を記録すること", func(t *testing.T) {
+		_, err := testee.Search(context.Background(), "products", `{"query":{"match_all":{}}}`)
+		assert.NoError(t, err)
+
+		span := recorder.Ended()[len(recorder.Ended())-1]
+		assert.Equal(t, "opensearch search products", span.Name())
+		assert.Contains(t, span.Attributes(), attribute.String("db.system.name", "opensearch"))
+		assert.Contains(t, span.Attributes(), attribute.String("db.collection.name", "products"))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Equal(t, 1, testutil.CollectAndCount(registry, "opensearch_request_duration_seconds"))
+	})
+
+	t.Run("エラーの場合はスパンをエラーにしエラーの件数を記録すること", func(t *testing.T) {
+		err := testee.IndexDocument(context.Background(), "products", "p-1", `{}`)
+		assert.Error(t, err)
+
+		span := recorder.Ended()[len(recorder.Ended())-1]
+		assert.Equal(t, "opensearch index products", span.Name())
+		assert.Contains(t, span.Attributes(), attribute.String("opensearch.document.id", "p-1"))
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, 1, testutil.CollectAndCount(registry, "opensearch_request_errors_total"))
+	})
+}
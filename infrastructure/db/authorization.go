@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+
+	"entgo.io/ent/privacy"
+	"github.com/t-kuni/cqrs-example/domain/authz"
+	"github.com/t-kuni/cqrs-example/ent"
+)
+
+// useAuthorization は product・テナント・membership の更新に authz の privacy ルールを適用するフックを登録します
+// privacy.DecisionContext(ctx, privacy.Allow) のコンテキストではルールを評価しません
+func useAuthorization(client *ent.Client) {
+	client.Product.Use(privacyHook(authz.ProductMutationRule()))
+	client.Tenant.Use(privacyHook(authz.TenantMutationRule()))
+	client.Membership.Use(privacyHook(authz.MembershipMutationRule()))
+}
+
+// privacyHook は privacy ルールを順に評価し、拒否された場合は更新を中断するフックを返します
+func privacyHook(rules ...privacy.MutationRule) ent.Hook {
+	policy := privacy.Policies{
+		privacy.Policy{Mutation: privacy.MutationPolicy(rules)},
+	}
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			if err := policy.EvalMutation(ctx, m); err != nil {
+				return nil, err
+			}
+			return next.Mutate(ctx, m)
+		})
+	}
+}
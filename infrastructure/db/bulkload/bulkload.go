@@ -0,0 +1,175 @@
+// Package bulkload は LOAD DATA LOCAL INFILE で任意のテーブルに大量の行を投入します
+// 行はファイルに書き出さずにイテレータからストリームで送信し、投入中はセカンダリインデックスと外部キーを削除して
+// 投入後に（失敗した場合も）復元します
+package bulkload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rotisserie/eris"
+)
+
+// Table は 投入先のテーブルと、行の値を投入する列です
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+// Options は 投入の設定です
+type Options struct {
+	// KeepKeys は セカンダリインデックスと外部キーを削除せずに投入します
+	// 少量の投入や、既に多くの行があり再作成のコストが大きいテーブルに使用します
+	KeepKeys bool
+	// DisableBinlog は sql_log_bin=0 にしてバイナリログに記録せずに投入します（SUPER 権限が必要です）
+	DisableBinlog bool
+}
+
+// readerSeq は LOAD DATA LOCAL INFILE の Reader の名前を一意にするための連番です
+var readerSeq atomic.Int64
+
+// Load は rows が返す行を table に投入し、投入した行数を返します
+// rows の各行の値は table.Columns と同じ順序で、nil は NULL として投入します
+// 投入は1つのトランザクションで行うため、rows がエラーを返した場合や投入に失敗した場合は1行も投入しません
+// 削除したインデックスと外部キーは、投入に失敗した場合やパニックした場合も復元します
+func Load(ctx context.Context, db *sql.DB, table Table, rows iter.Seq2[[]any, error], opts Options) (loaded int64, err error) {
+	conn, err := openSession(ctx, db, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer discardSession(conn)
+
+	if !opts.KeepKeys {
+		keys, err := readKeys(ctx, conn, table.Name)
+		if err != nil {
+			return 0, err
+		}
+		dropped, dropErr := dropKeys(ctx, conn, table.Name, keys)
+		defer func() {
+			// ctx がキャンセルされた場合や投入した接続が切断された場合も復元できるよう、新しい接続で復元する
+			restoreErr := restoreKeys(context.WithoutCancel(ctx), db, table.Name, dropped)
+			if restoreErr == nil {
+				return
+			}
+			if err != nil {
+				restoreErr = eris.Wrapf(restoreErr, "load failed: %v", err)
+			}
+			err = restoreErr
+		}()
+		if dropErr != nil {
+			return 0, dropErr
+		}
+	}
+
+	return stream(ctx, conn, table, rows)
+}
+
+// Truncate は 外部キーの制約を無視して tables を空にします
+func Truncate(ctx context.Context, db *sql.DB, tables ...string) error {
+	conn, err := openSession(ctx, db, Options{})
+	if err != nil {
+		return err
+	}
+	defer discardSession(conn)
+
+	for _, table := range tables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE "+quoteIdentifier(table)); err != nil {
+			return eris.Wrapf(err, "failed to truncate %s", table)
+		}
+	}
+	return nil
+}
+
+// openSession は 外部キーと一意性のチェックを無効にした接続を返します
+// セッションの設定がプールの他の利用者に影響しないよう、使用後は discardSession で破棄します
+func openSession(ctx context.Context, db *sql.DB, opts Options) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	statements := []string{"SET FOREIGN_KEY_CHECKS = 0", "SET UNIQUE_CHECKS = 0"}
+	if opts.DisableBinlog {
+		statements = append(statements, "SET sql_log_bin = 0")
+	}
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			discardSession(conn)
+			return nil, eris.Wrap(err, "")
+		}
+	}
+	return conn, nil
+}
+
+// discardSession は 接続をプールに戻さずに切断します
+func discardSession(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
+
+// stream は rows を CSV にエンコードしながら LOAD DATA LOCAL INFILE で送信します
+func stream(ctx context.Context, conn *sql.Conn, table Table, rows iter.Seq2[[]any, error]) (int64, error) {
+	pr, pw := io.Pipe()
+	name := fmt.Sprintf("bulkload_%s_%d", table.Name, readerSeq.Add(1))
+	mysql.RegisterReaderHandler(name, func() io.Reader { return pr })
+	defer mysql.DeregisterReaderHandler(name)
+
+	written := make(chan error, 1)
+	go func() {
+		err := writeRows(pw, rows, len(table.Columns))
+		pw.CloseWithError(err)
+		written <- err
+	}()
+
+	loaded, err := loadInTx(ctx, conn, table, name)
+	// 投入が途中で失敗した場合は、書き込み側がパイプで止まらないように読み込み側を閉じる
+	pr.CloseWithError(io.ErrClosedPipe)
+	if writeErr := <-written; writeErr != nil && writeErr != io.ErrClosedPipe {
+		return 0, eris.Wrapf(writeErr, "failed to read rows for %s", table.Name)
+	}
+	if err != nil {
+		return 0, eris.Wrapf(err, "failed to load %s", table.Name)
+	}
+	return loaded, nil
+}
+
+func loadInTx(ctx context.Context, conn *sql.Conn, table Table, readerName string) (int64, error) {
+	columns := make([]string, len(table.Columns))
+	for i, c := range table.Columns {
+		columns[i] = quoteIdentifier(c)
+	}
+	query := fmt.Sprintf(
+		"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 "+
+			`FIELDS TERMINATED BY ',' ENCLOSED BY '"' ESCAPED BY '\\' LINES TERMINATED BY '\n' (%s)`,
+		readerName, quoteIdentifier(table.Name), strings.Join(columns, ", "),
+	)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	loaded, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return loaded, tx.Commit()
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,97 @@
+package bulkload
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// writeRows は rows を LOAD DATA の形式（, 区切り、" で囲み、\ でエスケープ）にエンコードして w に書き込みます
+// rows がパニックした場合もエラーとして返し、投入を中断できるようにします
+func writeRows(w io.Writer, rows iter.Seq2[[]any, error], columns int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = eris.Errorf("panic while reading rows: %v", r)
+		}
+	}()
+
+	out := bufio.NewWriterSize(w, 64*1024)
+	line := 0
+	for row, rowErr := range rows {
+		line++
+		if rowErr != nil {
+			return rowErr
+		}
+		if len(row) != columns {
+			return eris.Errorf("row %d has %d values, expected %d", line, len(row), columns)
+		}
+		for i, v := range row {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			writeField(out, v)
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// writeField は 1つの値をエンコードします。nil は NULL を表す \N にします
+func writeField(out *bufio.Writer, v any) {
+	switch v := v.(type) {
+	case nil:
+		out.WriteString(`\N`)
+	case string:
+		writeQuoted(out, v)
+	case []byte:
+		writeQuoted(out, string(v))
+	case int:
+		out.WriteString(strconv.Itoa(v))
+	case int32:
+		out.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		out.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		out.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case bool:
+		if v {
+			out.WriteByte('1')
+		} else {
+			out.WriteByte('0')
+		}
+	case time.Time:
+		// ドライバと同様に UTC で投入する
+		writeQuoted(out, v.UTC().Format("2006-01-02 15:04:05.999999"))
+	case fmt.Stringer:
+		writeQuoted(out, v.String())
+	default:
+		writeQuoted(out, fmt.Sprint(v))
+	}
+}
+
+func writeQuoted(out *bufio.Writer, s string) {
+	out.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case 0:
+			out.WriteString(`\0`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte('"')
+}
@@ -0,0 +1,68 @@
+package bulkload
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func rowsOf(rows ...[]any) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for _, row := range rows {
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestWriteRows(t *testing.T) {
+	t.Run("LOAD DATA の形式にエンコードすること", func(t *testing.T) {
+		id := uuid.MustParse("0e1d5c7a-9b36-4c6e-8f0b-3a2f6d5e4c11")
+		listedAt := time.Date(2026, 1, 2, 12, 34, 56, 0, time.FixedZone("JST", 9*60*60))
+		var buf bytes.Buffer
+
+		err := writeRows(&buf, rowsOf(
+			[]any{id, "T-Shirt", int64(1200), []byte(`{"size":"M"}`), listedAt},
+			[]any{id, "say \"hi\"\nback\\slash", 0, nil, true},
+		), 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`"0e1d5c7a-9b36-4c6e-8f0b-3a2f6d5e4c11","T-Shirt",1200,"{\"size\":\"M\"}","2026-01-02 03:34:56"`+"\n"+
+				`"0e1d5c7a-9b36-4c6e-8f0b-3a2f6d5e4c11","say \"hi\"\nback\\slash",0,\N,1`+"\n",
+			buf.String(),
+		)
+	})
+
+	t.Run("イテレータのエラーを返すこと", func(t *testing.T) {
+		rows := func(yield func([]any, error) bool) {
+			if !yield([]any{"a"}, nil) {
+				return
+			}
+			yield(nil, errors.New("generator failed"))
+		}
+
+		err := writeRows(&bytes.Buffer{}, rows, 1)
+		assert.EqualError(t, err, "generator failed")
+	})
+
+	t.Run("列の数が異なる行はエラーになること", func(t *testing.T) {
+		err := writeRows(&bytes.Buffer{}, rowsOf([]any{"a", "b"}), 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("イテレータのパニックをエラーとして返すこと", func(t *testing.T) {
+		rows := func(yield func([]any, error) bool) {
+			panic("boom")
+		}
+
+		err := writeRows(&bytes.Buffer{}, rows, 1)
+		assert.ErrorContains(t, err, "boom")
+	})
+}
@@ -0,0 +1,132 @@
+package bulkload
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// key は SHOW CREATE TABLE から読み込んだセカンダリインデックスまたは外部キーの定義です
+type key struct {
+	Name string
+	// Definition は ALTER TABLE ... ADD に続けて指定できる定義（例: KEY `idx` (`a`,`b`)）です
+	Definition string
+}
+
+// tableKeys は テーブルのセカンダリインデックスと外部キーです（主キーと CHECK 制約は含みません）
+type tableKeys struct {
+	Indexes     []key
+	ForeignKeys []key
+}
+
+var (
+	indexDefinition      = regexp.MustCompile("^(?:UNIQUE |FULLTEXT |SPATIAL )?KEY `((?:[^`]|``)+)` ")
+	foreignKeyDefinition = regexp.MustCompile("^CONSTRAINT `((?:[^`]|``)+)` FOREIGN KEY ")
+)
+
+func readKeys(ctx context.Context, conn *sql.Conn, table string) (tableKeys, error) {
+	var name, createTable string
+	if err := conn.QueryRowContext(ctx, "SHOW CREATE TABLE "+quoteIdentifier(table)).Scan(&name, &createTable); err != nil {
+		return tableKeys{}, eris.Wrapf(err, "failed to read the definition of %s", table)
+	}
+	return parseKeys(createTable), nil
+}
+
+// parseKeys は SHOW CREATE TABLE の結果からセカンダリインデックスと外部キーの定義を取り出します
+// 定義をそのまま復元に使用するため、プレフィックス長・降順・関数インデックス・ON DELETE なども保持されます
+func parseKeys(createTable string) tableKeys {
+	var keys tableKeys
+	for _, line := range strings.Split(createTable, "\n") {
+		definition := strings.TrimSuffix(strings.TrimSpace(line), ",")
+		if m := indexDefinition.FindStringSubmatch(definition); m != nil {
+			keys.Indexes = append(keys.Indexes, key{Name: unquoteIdentifier(m[1]), Definition: definition})
+		} else if m := foreignKeyDefinition.FindStringSubmatch(definition); m != nil {
+			keys.ForeignKeys = append(keys.ForeignKeys, key{Name: unquoteIdentifier(m[1]), Definition: definition})
+		}
+	}
+	return keys
+}
+
+// dropKeys は 外部キー、インデックスの順に削除し、削除できたものを返します
+// 外部キーが使用しているインデックスは外部キーを削除するまで削除できないため、先に外部キーを削除します
+func dropKeys(ctx context.Context, conn *sql.Conn, table string, keys tableKeys) (tableKeys, error) {
+	var dropped tableKeys
+	if len(keys.ForeignKeys) > 0 {
+		if err := alterTable(ctx, conn, table, "DROP FOREIGN KEY", keys.ForeignKeys); err != nil {
+			return dropped, eris.Wrapf(err, "failed to drop foreign keys of %s", table)
+		}
+		dropped.ForeignKeys = keys.ForeignKeys
+	}
+	if len(keys.Indexes) > 0 {
+		if err := alterTable(ctx, conn, table, "DROP INDEX", keys.Indexes); err != nil {
+			return dropped, eris.Wrapf(err, "failed to drop indexes of %s", table)
+		}
+		dropped.Indexes = keys.Indexes
+	}
+	return dropped, nil
+}
+
+// restoreKeys は dropKeys で削除したインデックスと外部キーを再作成します
+// 外部キーの制約を無効にした接続で追加するため、投入した行の参照先の確認は行いません
+// 失敗した場合は手動で実行するための ALTER TABLE 文をエラーに含めます
+func restoreKeys(ctx context.Context, db *sql.DB, table string, dropped tableKeys) error {
+	if len(dropped.Indexes) == 0 && len(dropped.ForeignKeys) == 0 {
+		return nil
+	}
+
+	conn, err := openSession(ctx, db, Options{})
+	if err != nil {
+		return eris.Wrapf(err, "failed to restore keys of %s; run manually: %s", table, restoreStatements(table, dropped))
+	}
+	defer discardSession(conn)
+
+	// 全てのインデックスを1回の ALTER TABLE で追加し、テーブルの走査を1回にする
+	if len(dropped.Indexes) > 0 {
+		if err := alterTable(ctx, conn, table, "ADD", dropped.Indexes); err != nil {
+			return eris.Wrapf(err, "failed to restore indexes of %s; run manually: %s", table, restoreStatements(table, dropped))
+		}
+	}
+	if len(dropped.ForeignKeys) > 0 {
+		if err := alterTable(ctx, conn, table, "ADD", dropped.ForeignKeys); err != nil {
+			return eris.Wrapf(err, "failed to restore foreign keys of %s; run manually: %s", table, restoreStatements(table, tableKeys{ForeignKeys: dropped.ForeignKeys}))
+		}
+	}
+	return nil
+}
+
+func alterTable(ctx context.Context, conn *sql.Conn, table string, operation string, keys []key) error {
+	_, err := conn.ExecContext(ctx, alterTableStatement(table, operation, keys))
+	return err
+}
+
+// alterTableStatement は keys の全てに operation を行う ALTER TABLE 文を返します
+// ADD の場合は定義を、それ以外の場合は名前を operation に続けます
+func alterTableStatement(table string, operation string, keys []key) string {
+	clauses := make([]string, len(keys))
+	for i, k := range keys {
+		if operation == "ADD" {
+			clauses[i] = "ADD " + k.Definition
+		} else {
+			clauses[i] = operation + " " + quoteIdentifier(k.Name)
+		}
+	}
+	return "ALTER TABLE " + quoteIdentifier(table) + " " + strings.Join(clauses, ", ")
+}
+
+func restoreStatements(table string, keys tableKeys) string {
+	var statements []string
+	if len(keys.Indexes) > 0 {
+		statements = append(statements, alterTableStatement(table, "ADD", keys.Indexes)+";")
+	}
+	if len(keys.ForeignKeys) > 0 {
+		statements = append(statements, alterTableStatement(table, "ADD", keys.ForeignKeys)+";")
+	}
+	return strings.Join(statements, " ")
+}
+
+func unquoteIdentifier(name string) string {
+	return strings.ReplaceAll(name, "``", "`")
+}
@@ -0,0 +1,52 @@
+package bulkload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	createTable := "CREATE TABLE `products` (\n" +
+		"  `id` char(36) COLLATE utf8mb4_bin NOT NULL,\n" +
+		"  `tenant_id` char(36) COLLATE utf8mb4_bin NOT NULL,\n" +
+		"  `properties_size` varchar(64) COLLATE utf8mb4_bin GENERATED ALWAYS AS (json_unquote(json_extract(`properties`,_utf8mb4'$.size'))) VIRTUAL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `product_code` (`code`(16)),\n" +
+		"  KEY `product_tenant_id_properties_size` (`tenant_id`,`properties_size`),\n" +
+		"  CONSTRAINT `products_tenants_products` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`) ON DELETE CASCADE,\n" +
+		"  CONSTRAINT `products_chk_1` CHECK ((`price` >= 0))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"
+
+	keys := parseKeys(createTable)
+
+	assert.Equal(t, []key{
+		{Name: "product_code", Definition: "UNIQUE KEY `product_code` (`code`(16))"},
+		{Name: "product_tenant_id_properties_size", Definition: "KEY `product_tenant_id_properties_size` (`tenant_id`,`properties_size`)"},
+	}, keys.Indexes)
+	assert.Equal(t, []key{
+		{Name: "products_tenants_products", Definition: "CONSTRAINT `products_tenants_products` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`) ON DELETE CASCADE"},
+	}, keys.ForeignKeys)
+}
+
+func TestRestoreStatements(t *testing.T) {
+	keys := tableKeys{
+		Indexes: []key{
+			{Name: "product_tenant_id_price", Definition: "KEY `product_tenant_id_price` (`tenant_id`,`price`)"},
+			{Name: "product_category_id", Definition: "KEY `product_category_id` (`category_id`)"},
+		},
+		ForeignKeys: []key{
+			{Name: "products_tenants_products", Definition: "CONSTRAINT `products_tenants_products` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`)"},
+		},
+	}
+
+	assert.Equal(t,
+		"ALTER TABLE `products` DROP INDEX `product_tenant_id_price`, DROP INDEX `product_category_id`",
+		alterTableStatement("products", "DROP INDEX", keys.Indexes),
+	)
+	assert.Equal(t,
+		"ALTER TABLE `products` ADD KEY `product_tenant_id_price` (`tenant_id`,`price`), ADD KEY `product_category_id` (`category_id`); "+
+			"ALTER TABLE `products` ADD CONSTRAINT `products_tenants_products` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`);",
+		restoreStatements("products", keys),
+	)
+}
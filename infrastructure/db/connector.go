@@ -4,36 +4,66 @@ import (
 	"context"
 	"database/sql"
 	sql2 "entgo.io/ent/dialect/sql"
-	"entgo.io/ent/dialect/sql/schema"
-	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
 	"github.com/t-kuni/cqrs-example/ent"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
-	"os"
 )
 
 type Connector struct {
 	DB     *sql.DB
 	Client *ent.Client
+	// ReplicaDB と ReplicaClient は リードレプリカへの接続です（設定されていない場合は DB と Client と同じ）
+	ReplicaDB     *sql.DB
+	ReplicaClient *ent.Client
 }
 
-func NewConnector(lc fx.Lifecycle) (db.IConnector, error) {
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	database := os.Getenv("DB_DATABASE")
+// NewConnector は DBに接続し、コネクションプールの統計を registry に登録します
+// リードレプリカが設定されている場合はリードレプリカにも接続します（プールの統計の db_name は "<DB名>_replica"）
+func NewConnector(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, registry *prometheus.Registry) (db.IConnector, error) {
+	primary, err := openDB(lc, cfg.DB.DSN(), cfg.DB.Pool)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to connect to the primary")
+	}
+	if err := registry.Register(collectors.NewDBStatsCollector(primary, cfg.DB.Database)); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	client := newEntClient(primary, tp)
+
+	if !cfg.DB.HasReplica() {
+		return &Connector{DB: primary, Client: client, ReplicaDB: primary, ReplicaClient: client}, nil
+	}
+
+	replica, err := openDB(lc, cfg.DB.ReplicaDSN(), cfg.DB.Pool)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to connect to the replica")
+	}
+	if err := registry.Register(collectors.NewDBStatsCollector(replica, cfg.DB.Database+"_replica")); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	return &Connector{DB: primary, Client: client, ReplicaDB: replica, ReplicaClient: newEntClient(replica, tp)}, nil
+}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&allowAllFiles=true", user, password, host, port, database)
+// openDB は コネクションプールを設定してDBに接続し、アプリケーションの停止時に切断します
+func openDB(lc fx.Lifecycle, dsn string, pool config.Pool) (*sql.DB, error) {
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return nil, err
+		return nil, eris.Wrap(err, "")
 	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	if err := db.Ping(); err != nil {
-		return nil, err
+		db.Close()
+		return nil, eris.Wrap(err, "")
 	}
 
 	lc.Append(fx.Hook{
@@ -41,10 +71,17 @@ func NewConnector(lc fx.Lifecycle) (db.IConnector, error) {
 			return db.Close()
 		},
 	})
+	return db, nil
+}
 
-	drv := sql2.OpenDB("mysql", db)
+// newEntClient は クエリのトレースとテナントスコープなどのフックを設定した ent クライアントを生成します
+func newEntClient(db *sql.DB, tp trace.TracerProvider) *ent.Client {
+	drv := NewTracingDriver(sql2.OpenDB("mysql", db), tp)
 	client := ent.NewClient(ent.Driver(drv))
-	return &Connector{DB: db, Client: client}, nil
+	useVersionHooks(client)
+	useTenantScope(client)
+	useAuthorization(client)
+	return client
 }
 
 func (c Connector) GetDB() *sql.DB {
@@ -55,34 +92,15 @@ func (c Connector) GetEnt() *ent.Client {
 	return c.Client
 }
 
-func (c Connector) Migrate(ctx context.Context, opts ...schema.MigrateOption) error {
-	return c.Client.Schema.Create(ctx, opts...)
+func (c Connector) GetReplicaEnt() *ent.Client {
+	return c.ReplicaClient
 }
 
-func (c Connector) Transaction(ctx context.Context, fn func(tx *ent.Client) error) error {
-	tx, err := c.Client.Tx(ctx)
-	if err != nil {
-		return eris.Wrap(err, "")
-	}
-	defer func() {
-		if v := recover(); v != nil {
-			tx.Rollback()
-			panic(v)
-		}
-	}()
-	txClient := tx.Client()
-	if err := fn(txClient); err != nil {
-		if rerr := tx.Rollback(); rerr != nil {
-			err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+func (c Connector) Shutdown() error {
+	if c.ReplicaDB != c.DB {
+		if err := c.ReplicaDB.Close(); err != nil {
+			return eris.Wrap(err, "")
 		}
-		return eris.Wrap(err, "")
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("committing transaction: %w", err)
 	}
-	return nil
-}
-
-func (c Connector) Shutdown() error {
 	return c.DB.Close()
 }
@@ -3,17 +3,15 @@ package db
 import (
 	"context"
 	"database/sql"
-	sql2 "entgo.io/ent/dialect/sql"
-	"fmt"
 	"github.com/DATA-DOG/go-txdb"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/t-kuni/cqrs-example/config"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
-	"github.com/t-kuni/cqrs-example/ent"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
-	"os"
 )
 
-func NewTestConnector(lc fx.Lifecycle) (db.IConnector, error) {
+func NewTestConnector(lc fx.Lifecycle, tp trace.TracerProvider) (db.IConnector, error) {
 	db, err := sql.Open("txdb", "identifier")
 	if err != nil {
 		return nil, err
@@ -29,19 +27,12 @@ func NewTestConnector(lc fx.Lifecycle) (db.IConnector, error) {
 		},
 	})
 
-	drv := sql2.OpenDB("mysql", db)
-	client := ent.NewClient(ent.Driver(drv))
-	return &Connector{DB: db, Client: client}, nil
+	// テストデータはロールバックされるトランザクション内にしか存在しないため、参照系もプライマリと同じ接続を使用する
+	client := newEntClient(db, tp)
+	return &Connector{DB: db, Client: client, ReplicaDB: db, ReplicaClient: client}, nil
 }
 
 // RegisterTxdbDriver 自動でロールバックする単一トランザクションのDBドライバを登録する（テスト用）
-func RegisterTxdbDriver() {
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	database := os.Getenv("DB_DATABASE")
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&allowAllFiles=true", user, password, host, port, database)
-	txdb.Register("txdb", "mysql", dsn)
+func RegisterTxdbDriver(cfg config.DB) {
+	txdb.Register("txdb", "mysql", cfg.DSN())
 }
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// dumpInsertBatchSize は ダンプの1つの INSERT 文にまとめる最大の行数です
+const dumpInsertBatchSize = 500
+
+// DumpDatabase は 接続先のデータベースの全てのテーブルの定義とレコードを、mysqldump と同様の SQL として w に書き出します
+// 書き出した SQL を mysql コマンドなどで実行するとテーブルを再作成してレコードを復元できます
+// mysqldump の --single-transaction と同様に、読み取り専用のトランザクションの一貫したスナップショットから読み込みます
+func DumpDatabase(ctx context.Context, db *sql.DB, w io.Writer) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer conn.Close()
+
+	// TIMESTAMP の値をセッションのタイムゾーンに依存せずに復元できるよう UTC で読み書きする
+	if _, err := conn.ExecContext(ctx, "SET time_zone = '+00:00'"); err != nil {
+		return eris.Wrap(err, "")
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer tx.Rollback()
+
+	var database string
+	if err := tx.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+		return eris.Wrap(err, "")
+	}
+	tables, err := dumpTableNames(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "-- Dump of `%s` at %s\n", database, time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintln(out, "SET NAMES utf8mb4;")
+	fmt.Fprintln(out, "SET time_zone = '+00:00';")
+	fmt.Fprintln(out, "SET FOREIGN_KEY_CHECKS = 0;")
+	for _, table := range tables {
+		if err := dumpTable(ctx, tx, out, table); err != nil {
+			return eris.Wrapf(err, "failed to dump %s", table)
+		}
+	}
+	fmt.Fprintln(out, "SET FOREIGN_KEY_CHECKS = 1;")
+	return eris.Wrap(out.Flush(), "")
+}
+
+func dumpTableNames(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name",
+	)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		tables = append(tables, table)
+	}
+	return tables, eris.Wrap(rows.Err(), "")
+}
+
+func dumpTable(ctx context.Context, tx *sql.Tx, out *bufio.Writer, table string) error {
+	var name, createTable string
+	if err := tx.QueryRowContext(ctx, "SHOW CREATE TABLE "+quoteIdentifier(table)).Scan(&name, &createTable); err != nil {
+		return eris.Wrap(err, "")
+	}
+	fmt.Fprintf(out, "\n-- Table %s\n", quoteIdentifier(table))
+	fmt.Fprintf(out, "DROP TABLE IF EXISTS %s;\n", quoteIdentifier(table))
+	fmt.Fprintf(out, "%s;\n", createTable)
+
+	columns, err := dumpColumnNames(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), quoteIdentifier(table)))
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", quoteIdentifier(table), strings.Join(columns, ", "))
+
+	values := make([]any, len(columnTypes))
+	pointers := make([]any, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	literals := make([]string, len(columnTypes))
+	inBatch := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return eris.Wrap(err, "")
+		}
+		for i, v := range values {
+			literals[i] = sqlLiteral(v, columnTypes[i].DatabaseTypeName())
+		}
+
+		if inBatch == 0 {
+			out.WriteString(insert)
+		} else {
+			out.WriteString(",\n")
+		}
+		out.WriteString("(" + strings.Join(literals, ", ") + ")")
+		inBatch++
+		if inBatch == dumpInsertBatchSize {
+			out.WriteString(";\n")
+			inBatch = 0
+		}
+	}
+	if inBatch > 0 {
+		out.WriteString(";\n")
+	}
+	return eris.Wrap(rows.Err(), "")
+}
+
+// dumpColumnNames は テーブルの列のうち、値を INSERT できる列（生成列以外）の引用符付きの名前を返します
+func dumpColumnNames(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND generation_expression = '' ORDER BY ordinal_position",
+		table,
+	)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		columns = append(columns, quoteIdentifier(column))
+	}
+	return columns, eris.Wrap(rows.Err(), "")
+}
+
+// sqlLiteral は 読み込んだ値を INSERT 文に埋め込める SQL のリテラルに変換します
+// バイナリ型の値は16進数のリテラルにします
+func sqlLiteral(v any, databaseType string) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		if strings.Contains(databaseType, "BLOB") || strings.Contains(databaseType, "BINARY") {
+			if len(v) == 0 {
+				return "''"
+			}
+			return fmt.Sprintf("0x%X", v)
+		}
+		return quoteString(string(v))
+	default:
+		return quoteString(fmt.Sprint(v))
+	}
+}
+
+// quoteString は MySQL の文字列リテラルとしてエスケープして引用符で囲みます
+func quoteString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
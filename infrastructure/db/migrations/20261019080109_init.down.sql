@@ -0,0 +1,20 @@
+-- reverse: create "products" table
+DROP TABLE `products`;
+-- reverse: create "categories" table
+DROP TABLE `categories`;
+-- reverse: create "memberships" table
+DROP TABLE `memberships`;
+-- reverse: create "tenants" table
+DROP TABLE `tenants`;
+-- reverse: create "users" table
+DROP TABLE `users`;
+-- reverse: create "idempotency_keys" table
+DROP TABLE `idempotency_keys`;
+-- reverse: create "event_subscriber_offsets" table
+DROP TABLE `event_subscriber_offsets`;
+-- reverse: create "domain_events" table
+DROP TABLE `domain_events`;
+-- reverse: create "aggregate_snapshots" table
+DROP TABLE `aggregate_snapshots`;
+-- reverse: create "events" table
+DROP TABLE `events`;
@@ -0,0 +1,20 @@
+-- create "events" table
+CREATE TABLE `events` (`id` bigint NOT NULL AUTO_INCREMENT, `aggregate_type` varchar(255) NOT NULL, `aggregate_id` char(36) NOT NULL, `sequence` bigint NOT NULL, `type` varchar(255) NOT NULL, `payload` json NOT NULL, `occurred_at` timestamp NOT NULL, PRIMARY KEY (`id`), UNIQUE INDEX `aggregateevent_aggregate_id_sequence` (`aggregate_id`, `sequence`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "aggregate_snapshots" table
+CREATE TABLE `aggregate_snapshots` (`id` char(36) NOT NULL, `aggregate_type` varchar(255) NOT NULL, `sequence` bigint NOT NULL, `state` json NOT NULL, `created_at` timestamp NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "domain_events" table
+CREATE TABLE `domain_events` (`id` bigint NOT NULL AUTO_INCREMENT, `name` varchar(255) NOT NULL, `payload` json NOT NULL, `occurred_at` timestamp NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "event_subscriber_offsets" table
+CREATE TABLE `event_subscriber_offsets` (`id` varchar(255) NOT NULL, `last_event_id` bigint NOT NULL, `updated_at` timestamp NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "idempotency_keys" table
+CREATE TABLE `idempotency_keys` (`id` varchar(255) NOT NULL, `request_hash` varchar(255) NOT NULL, `status_code` bigint NOT NULL DEFAULT 0, `response_header` json NULL, `response_body` blob NULL, `created_at` timestamp NOT NULL, `expires_at` timestamp NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "users" table
+CREATE TABLE `users` (`id` char(36) NOT NULL, `name` varchar(255) NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "tenants" table
+CREATE TABLE `tenants` (`id` char(36) NOT NULL, `version` bigint NOT NULL DEFAULT 1, `name` varchar(255) NOT NULL, `owner_id` char(36) NOT NULL, PRIMARY KEY (`id`), CONSTRAINT `tenants_users_tenants` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`) ON DELETE NO ACTION) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "memberships" table
+CREATE TABLE `memberships` (`id` char(36) NOT NULL, `role` enum('owner','admin','member') NOT NULL DEFAULT 'member', `created_at` timestamp NOT NULL, `tenant_id` char(36) NOT NULL, `user_id` char(36) NOT NULL, PRIMARY KEY (`id`), UNIQUE INDEX `membership_tenant_id_user_id` (`tenant_id`, `user_id`), CONSTRAINT `memberships_tenants_memberships` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`) ON DELETE CASCADE, CONSTRAINT `memberships_users_memberships` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "categories" table
+CREATE TABLE `categories` (`id` char(36) NOT NULL, `name` varchar(255) NOT NULL, PRIMARY KEY (`id`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "products" table
+CREATE TABLE `products` (`id` char(36) NOT NULL, `version` bigint NOT NULL DEFAULT 1, `name` varchar(255) NOT NULL, `price` bigint NOT NULL, `properties` json NOT NULL, `listed_at` timestamp NOT NULL, `category_id` char(36) NOT NULL, `tenant_id` char(36) NOT NULL, PRIMARY KEY (`id`), CONSTRAINT `products_categories_products` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON DELETE NO ACTION, CONSTRAINT `products_tenants_products` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`) ON DELETE NO ACTION) CHARSET utf8mb4 COLLATE utf8mb4_bin;
@@ -0,0 +1,11 @@
+-- reverse: index generated columns of "properties"
+ALTER TABLE `products` DROP INDEX `product_tenant_id_properties_color`, DROP INDEX `product_tenant_id_properties_size`;
+-- reverse: add generated columns for "properties" size / color (ent/schema cannot express generated columns)
+ALTER TABLE `products` DROP COLUMN `properties_color`, DROP COLUMN `properties_size`;
+-- restore the indexes of foreign keys, which MySQL dropped when the indexes below were added
+ALTER TABLE `tenants` ADD INDEX `tenants_users_tenants` (`owner_id`);
+ALTER TABLE `products` ADD INDEX `products_categories_products` (`category_id`), ADD INDEX `products_tenants_products` (`tenant_id`);
+-- reverse: modify "tenants" table
+ALTER TABLE `tenants` DROP INDEX `tenant_name`, DROP INDEX `tenant_owner_id`;
+-- reverse: modify "products" table
+ALTER TABLE `products` DROP INDEX `product_category_id`, DROP INDEX `product_tenant_id_price`, DROP INDEX `product_tenant_id_listed_at`, DROP INDEX `product_tenant_id_category_id`;
@@ -0,0 +1,8 @@
+-- modify "products" table
+ALTER TABLE `products` ADD INDEX `product_tenant_id_category_id` (`tenant_id`, `category_id`), ADD INDEX `product_tenant_id_listed_at` (`tenant_id`, `listed_at`), ADD INDEX `product_tenant_id_price` (`tenant_id`, `price`), ADD INDEX `product_category_id` (`category_id`);
+-- modify "tenants" table
+ALTER TABLE `tenants` ADD INDEX `tenant_owner_id` (`owner_id`), ADD INDEX `tenant_name` (`name`);
+-- add generated columns for "properties" size / color (ent/schema cannot express generated columns)
+ALTER TABLE `products` ADD COLUMN `properties_size` varchar(64) GENERATED ALWAYS AS (json_unquote(json_extract(`properties`, '$.size'))) VIRTUAL NULL, ADD COLUMN `properties_color` varchar(64) GENERATED ALWAYS AS (json_unquote(json_extract(`properties`, '$.color'))) VIRTUAL NULL;
+-- index generated columns of "properties"
+ALTER TABLE `products` ADD INDEX `product_tenant_id_properties_size` (`tenant_id`, `properties_size`), ADD INDEX `product_tenant_id_properties_color` (`tenant_id`, `properties_color`);
@@ -0,0 +1,4 @@
+-- scoped keys cannot be converted back to unscoped keys
+DELETE FROM `idempotency_keys`;
+-- reverse: modify "idempotency_keys" table
+ALTER TABLE `idempotency_keys` DROP INDEX `idempotencykey_principal_id_tenant_id_key`, DROP COLUMN `key`, DROP COLUMN `tenant_id`, DROP COLUMN `principal_id`, MODIFY COLUMN `id` varchar(255) NOT NULL;
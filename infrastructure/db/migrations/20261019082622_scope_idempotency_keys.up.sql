@@ -0,0 +1,4 @@
+-- existing keys are not tied to a principal / tenant and cannot be replayed safely
+DELETE FROM `idempotency_keys`;
+-- modify "idempotency_keys" table
+ALTER TABLE `idempotency_keys` MODIFY COLUMN `id` bigint NOT NULL AUTO_INCREMENT, ADD COLUMN `principal_id` char(36) NOT NULL, ADD COLUMN `tenant_id` char(36) NOT NULL, ADD COLUMN `key` varchar(255) NOT NULL, ADD UNIQUE INDEX `idempotencykey_principal_id_tenant_id_key` (`principal_id`, `tenant_id`, `key`);
@@ -0,0 +1,411 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+)
+
+// migrationFiles は ent/schema の差分から生成したマイグレーションファイルです
+// ファイルは `go run commands/migrate/main.go diff <名前>` で生成します
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	// migrationLockName は マイグレーションを同時に実行しないための名前付きロック（GET_LOCK）の名前です
+	migrationLockName = "schema_migrations"
+	// migrationLockTimeout は 名前付きロックの取得を待つ秒数です
+	migrationLockTimeout = 10
+)
+
+const createMigrationHistoryTable = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`version` varchar(32) NOT NULL, " +
+	"`name` varchar(255) NOT NULL, " +
+	"`checksum` char(64) NOT NULL, " +
+	"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+	"PRIMARY KEY (`version`)" +
+	") CHARSET utf8mb4 COLLATE utf8mb4_bin"
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// AppliedMigration は schema_migrations に記録されている適用済みのマイグレーションです
+type AppliedMigration struct {
+	Version   string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator は IMigrator の実装です
+// MySQL の DDL は暗黙的にコミットされるため、マイグレーションごとに全ての文の実行に成功した時点で適用履歴を記録します
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []db.Migration
+}
+
+// NewMigrator は infrastructure/db/migrations に埋め込んだマイグレーションをプライマリに適用する IMigrator を生成します
+func NewMigrator(conn db.IConnector) (db.IMigrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	migrations, err := LoadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: conn.GetDB(), Migrations: migrations}, nil
+}
+
+// LoadMigrations は fsys 直下の <バージョン>_<名前>.up.sql と .down.sql を読み込み、バージョン順に返します
+// それ以外のファイル（atlas.sum など）は無視します
+func LoadMigrations(fsys fs.FS) ([]db.Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	byVersion := map[string]*db.Migration{}
+	for _, entry := range entries {
+		matches := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, name, direction := matches[1], matches[2], matches[3]
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &db.Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, eris.Errorf("migration version %s is used by both %q and %q", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = splitStatements(string(content))
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = splitStatements(string(content))
+		}
+	}
+
+	migrations := make([]db.Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, eris.Errorf("migration %s_%s has no up.sql", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// PlanUp は 未適用のマイグレーションを古い順に最大 steps 件（0 の場合は全て）返します
+// DBがコードより新しい場合や適用済みのファイルが変更されている場合はエラーを返します
+func PlanUp(migrations []db.Migration, applied []AppliedMigration, steps int) ([]db.Migration, error) {
+	if err := checkHistory(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	done := map[string]bool{}
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	var pending []db.Migration
+	for _, migration := range migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	if steps > 0 && len(pending) > steps {
+		pending = pending[:steps]
+	}
+	return pending, nil
+}
+
+// PlanDown は ロールバックする適用済みのマイグレーションを新しい順に最大 steps 件（0 の場合は全て）返します
+// DBがコードより新しい場合や適用済みのファイルが変更されている場合はエラーを返します
+func PlanDown(migrations []db.Migration, applied []AppliedMigration, steps int) ([]db.Migration, error) {
+	if err := checkHistory(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	done := map[string]bool{}
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	var rollback []db.Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if done[migrations[i].Version] {
+			rollback = append(rollback, migrations[i])
+		}
+	}
+	if steps > 0 && len(rollback) > steps {
+		rollback = rollback[:steps]
+	}
+	return rollback, nil
+}
+
+// checkHistory は 適用済みのマイグレーションが全てコードに存在し、内容が変更されていないことを確認します
+func checkHistory(migrations []db.Migration, applied []AppliedMigration) error {
+	byVersion := map[string]db.Migration{}
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+	for _, a := range applied {
+		migration, ok := byVersion[a.Version]
+		if !ok {
+			return eris.Wrapf(db.ErrDatabaseAhead, "migration %s is applied to the database but does not exist in the code", a.Version)
+		}
+		if migration.Checksum != a.Checksum {
+			return eris.Wrapf(db.ErrMigrationModified, "%s_%s.up.sql was changed after it was applied", migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Status は コードとDBの全てのマイグレーションの適用状況をバージョン順に返します
+func (m *Migrator) Status(ctx context.Context) ([]db.MigrationStatus, error) {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defer conn.Close()
+
+	applied, err := readMigrationHistory(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	appliedByVersion := map[string]AppliedMigration{}
+	for _, a := range applied {
+		appliedByVersion[a.Version] = a
+	}
+
+	statuses := make([]db.MigrationStatus, 0, len(m.Migrations))
+	known := map[string]bool{}
+	for _, migration := range m.Migrations {
+		known[migration.Version] = true
+		status := db.MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := appliedByVersion[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = a.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range applied {
+		if known[a.Version] {
+			continue
+		}
+		appliedAt := a.AppliedAt
+		statuses = append(statuses, db.MigrationStatus{Version: a.Version, AppliedAt: &appliedAt, Unknown: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Up は 未適用のマイグレーションを古い順に適用します
+func (m *Migrator) Up(ctx context.Context, steps int, dryRun bool) ([]db.Migration, error) {
+	return m.migrate(ctx, dryRun, func(applied []AppliedMigration) ([]db.Migration, error) {
+		return PlanUp(m.Migrations, applied, steps)
+	}, applyMigration)
+}
+
+// Down は 適用済みのマイグレーションを新しい順にロールバックします
+func (m *Migrator) Down(ctx context.Context, steps int, dryRun bool) ([]db.Migration, error) {
+	return m.migrate(ctx, dryRun, func(applied []AppliedMigration) ([]db.Migration, error) {
+		return PlanDown(m.Migrations, applied, steps)
+	}, rollbackMigration)
+}
+
+// migrate は 名前付きロックを取得してから plan で決めたマイグレーションを1件ずつ run で実行し、実行したマイグレーションを返します
+// dryRun の場合はロックの取得と履歴テーブルの作成も行わずに、実行するマイグレーションを返します
+func (m *Migrator) migrate(
+	ctx context.Context,
+	dryRun bool,
+	plan func(applied []AppliedMigration) ([]db.Migration, error),
+	run func(ctx context.Context, conn *sql.Conn, migration db.Migration) error,
+) ([]db.Migration, error) {
+	// GET_LOCK は接続ごとのロックのため、全ての文を同じ接続で実行する
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defer conn.Close()
+
+	if !dryRun {
+		unlock, err := lockMigrations(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		if _, err := conn.ExecContext(ctx, createMigrationHistoryTable); err != nil {
+			return nil, eris.Wrap(err, "failed to create schema_migrations")
+		}
+	}
+
+	applied, err := readMigrationHistory(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := plan(applied)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return migrations, nil
+	}
+
+	for i, migration := range migrations {
+		if err := run(ctx, conn, migration); err != nil {
+			return migrations[:i], err
+		}
+	}
+	return migrations, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, migration db.Migration) error {
+	if err := execMigrationStatements(ctx, conn, migration, migration.Up); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx,
+		"INSERT INTO `schema_migrations` (`version`, `name`, `checksum`) VALUES (?, ?, ?)",
+		migration.Version, migration.Name, migration.Checksum,
+	)
+	return eris.Wrap(err, "")
+}
+
+func rollbackMigration(ctx context.Context, conn *sql.Conn, migration db.Migration) error {
+	if err := execMigrationStatements(ctx, conn, migration, migration.Down); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "DELETE FROM `schema_migrations` WHERE `version` = ?", migration.Version)
+	return eris.Wrap(err, "")
+}
+
+func execMigrationStatements(ctx context.Context, conn *sql.Conn, migration db.Migration, statements []string) error {
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			// 失敗した文より前の文はロールバックされないため、DBを手動で修復する必要がある
+			return eris.Wrapf(err, "migration %s_%s failed (statements before the failure are not rolled back): %s", migration.Version, migration.Name, statement)
+		}
+	}
+	return nil
+}
+
+// lockMigrations は 他のプロセスとマイグレーションを同時に実行しないよう名前付きロックを取得し、解放する関数を返します
+func lockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&acquired); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if acquired.Int64 != 1 {
+		return nil, eris.Errorf("another migration is running (could not acquire the lock %q within %ds)", migrationLockName, migrationLockTimeout)
+	}
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+	}, nil
+}
+
+// readMigrationHistory は schema_migrations の適用履歴を返します（テーブルが無い場合は空）
+func readMigrationHistory(ctx context.Context, conn *sql.Conn) ([]AppliedMigration, error) {
+	var exists int
+	err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'",
+	).Scan(&exists)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	if exists == 0 {
+		return nil, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT `version`, `checksum`, `applied_at` FROM `schema_migrations` ORDER BY `version`")
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		applied = append(applied, a)
+	}
+	return applied, eris.Wrap(rows.Err(), "")
+}
+
+// splitStatements は SQLファイルの内容をコメントを除いた文ごとに分割します
+// 文字列・引用符付きの識別子の中の ; とコメントの開始記号は区切りとして扱いません
+func splitStatements(src string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		// quote は 文字列・識別子の中を読んでいる場合の引用符です
+		quote byte
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(src) {
+				i++
+				current.WriteByte(src[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteByte(c)
+		case c == '#' || (c == '-' && strings.HasPrefix(src[i:], "-- ")):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,125 @@
+package db_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	domainDB "github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/infrastructure/db"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("up.sql と down.sql をバージョン順に読み込み、文ごとに分割すること", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"20260102000000_add_price.up.sql":   {Data: []byte("-- modify \"products\" table\nALTER TABLE `products` ADD COLUMN `price` bigint NOT NULL;\n")},
+			"20260102000000_add_price.down.sql": {Data: []byte("ALTER TABLE `products` DROP COLUMN `price`;\n")},
+			"20260101000000_init.up.sql": {Data: []byte(
+				"CREATE TABLE `a` (`id` bigint, `note` varchar(255) DEFAULT 'x;y');\n" +
+					"/* comment; */ CREATE TABLE `b` (`id` bigint);\n",
+			)},
+			"20260101000000_init.down.sql": {Data: []byte("DROP TABLE `b`;\nDROP TABLE `a`;\n")},
+			"atlas.sum":                    {Data: []byte("h1:xxx\n")},
+		}
+
+		migrations, err := db.LoadMigrations(fsys)
+		assert.NoError(t, err)
+
+		assert.Len(t, migrations, 2)
+		assert.Equal(t, "20260101000000", migrations[0].Version)
+		assert.Equal(t, "init", migrations[0].Name)
+		assert.Equal(t, []string{
+			"CREATE TABLE `a` (`id` bigint, `note` varchar(255) DEFAULT 'x;y')",
+			"CREATE TABLE `b` (`id` bigint)",
+		}, migrations[0].Up)
+		assert.Equal(t, []string{"DROP TABLE `b`", "DROP TABLE `a`"}, migrations[0].Down)
+		assert.Len(t, migrations[0].Checksum, 64)
+		assert.Equal(t, "add_price", migrations[1].Name)
+		assert.Equal(t, []string{"ALTER TABLE `products` ADD COLUMN `price` bigint NOT NULL"}, migrations[1].Up)
+	})
+
+	t.Run("リポジトリのマイグレーションファイルを読み込めること", func(t *testing.T) {
+		migrations, err := db.LoadMigrations(os.DirFS("migrations"))
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, migrations)
+		for _, m := range migrations {
+			assert.NotEmpty(t, m.Up, m.Version)
+			assert.NotEmpty(t, m.Down, m.Version)
+		}
+	})
+
+	t.Run("up.sql が無い場合はエラーになること", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"20260101000000_init.down.sql": {Data: []byte("DROP TABLE `a`;")},
+		}
+
+		_, err := db.LoadMigrations(fsys)
+		assert.Error(t, err)
+	})
+}
+
+func TestPlanUp(t *testing.T) {
+	migrations := []domainDB.Migration{
+		{Version: "1", Name: "init", Checksum: "c1"},
+		{Version: "2", Name: "add_index", Checksum: "c2"},
+		{Version: "3", Name: "add_column", Checksum: "c3"},
+	}
+
+	t.Run("未適用のマイグレーションを古い順に返すこと", func(t *testing.T) {
+		pending, err := db.PlanUp(migrations, []db.AppliedMigration{{Version: "1", Checksum: "c1"}}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2", "3"}, versions(pending))
+	})
+
+	t.Run("steps の件数までに制限すること", func(t *testing.T) {
+		pending, err := db.PlanUp(migrations, nil, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "2"}, versions(pending))
+	})
+
+	t.Run("DBにコードに存在しないマイグレーションが適用されている場合はエラーになること", func(t *testing.T) {
+		applied := []db.AppliedMigration{{Version: "1", Checksum: "c1"}, {Version: "4", Checksum: "c4"}}
+
+		_, err := db.PlanUp(migrations, applied, 0)
+		assert.ErrorIs(t, err, domainDB.ErrDatabaseAhead)
+	})
+
+	t.Run("適用済みのファイルが変更されている場合はエラーになること", func(t *testing.T) {
+		_, err := db.PlanUp(migrations, []db.AppliedMigration{{Version: "1", Checksum: "changed"}}, 0)
+		assert.ErrorIs(t, err, domainDB.ErrMigrationModified)
+	})
+}
+
+func TestPlanDown(t *testing.T) {
+	migrations := []domainDB.Migration{
+		{Version: "1", Name: "init", Checksum: "c1"},
+		{Version: "2", Name: "add_index", Checksum: "c2"},
+		{Version: "3", Name: "add_column", Checksum: "c3"},
+	}
+	applied := []db.AppliedMigration{{Version: "1", Checksum: "c1"}, {Version: "2", Checksum: "c2"}}
+
+	t.Run("適用済みのマイグレーションを新しい順に steps 件返すこと", func(t *testing.T) {
+		rollback, err := db.PlanDown(migrations, applied, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2"}, versions(rollback))
+
+		rollback, err = db.PlanDown(migrations, applied, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2", "1"}, versions(rollback))
+	})
+
+	t.Run("DBにコードに存在しないマイグレーションが適用されている場合はエラーになること", func(t *testing.T) {
+		_, err := db.PlanDown(migrations, append(applied, db.AppliedMigration{Version: "4", Checksum: "c4"}), 1)
+		assert.ErrorIs(t, err, domainDB.ErrDatabaseAhead)
+	})
+}
+
+func versions(migrations []domainDB.Migration) []string {
+	var vs []string
+	for _, m := range migrations {
+		vs = append(vs, m.Version)
+	}
+	return vs
+}
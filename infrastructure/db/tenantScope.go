@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+
+	entgo "entgo.io/ent"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/hook"
+	"github.com/t-kuni/cqrs-example/ent/product"
+)
+
+// useTenantScope は product の参照・更新をコンテキストのテナントに限定するインターセプタとフックを登録します
+// コンテキストがテナントの範囲に制限されていない場合（バッチ処理など）は何もしません
+func useTenantScope(client *ent.Client) {
+	client.Product.Intercept(productTenantInterceptor())
+	client.Product.Use(productTenantHook())
+}
+
+// productTenantInterceptor は product のクエリ（エッジの走査を含む）にテナントの条件を付与します
+func productTenantInterceptor() ent.Interceptor {
+	return entgo.TraverseFunc(func(ctx context.Context, q entgo.Query) error {
+		tenantID, ok, err := tenancy.Resolve(ctx)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if !ok {
+			return nil
+		}
+
+		pq, isProductQuery := q.(*ent.ProductQuery)
+		if !isProductQuery {
+			return eris.Errorf("unexpected query type %T", q)
+		}
+		pq.Where(product.TenantID(tenantID))
+		return nil
+	})
+}
+
+// productTenantHook は product の更新・削除にテナントの条件を付与し、他のテナントへの登録・移動を拒否します
+func productTenantHook() ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return hook.ProductFunc(func(ctx context.Context, m *ent.ProductMutation) (ent.Value, error) {
+			tenantID, ok, err := tenancy.Resolve(ctx)
+			if err != nil {
+				return nil, eris.Wrap(err, "")
+			}
+			if !ok {
+				return next.Mutate(ctx, m)
+			}
+
+			if mutated, exists := m.TenantID(); exists && mutated != tenantID {
+				return nil, eris.Wrap(tenancy.ErrTenantMismatch, "")
+			}
+			if m.Op().Is(ent.OpCreate) {
+				m.SetTenantID(tenantID)
+				return next.Mutate(ctx, m)
+			}
+
+			m.Where(product.TenantID(tenantID))
+			return next.Mutate(ctx, m)
+		})
+	}
+}
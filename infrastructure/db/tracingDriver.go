@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/t-kuni/cqrs-example/infrastructure/db"
+
+// TracingDriver は ent が発行するクエリとトランザクションごとに OpenTelemetry のスパンを記録する dialect.Driver です
+// スパン名はSQLの操作（SELECT, INSERT など）、属性にSQL文を記録します（引数は記録しません）
+type TracingDriver struct {
+	dialect.Driver
+	tracer trace.Tracer
+}
+
+// NewTracingDriver は drv の操作をトレースする dialect.Driver を返します
+func NewTracingDriver(drv dialect.Driver, tp trace.TracerProvider) dialect.Driver {
+	return &TracingDriver{
+		Driver: drv,
+		tracer: tp.Tracer(tracerName),
+	}
+}
+
+func (d *TracingDriver) Exec(ctx context.Context, query string, args, v any) error {
+	return traceQuery(ctx, d.tracer, d.Dialect(), query, func(ctx context.Context) error {
+		return d.Driver.Exec(ctx, query, args, v)
+	})
+}
+
+func (d *TracingDriver) Query(ctx context.Context, query string, args, v any) error {
+	return traceQuery(ctx, d.tracer, d.Dialect(), query, func(ctx context.Context) error {
+		return d.Driver.Query(ctx, query, args, v)
+	})
+}
+
+func (d *TracingDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	return d.BeginTx(ctx, nil)
+}
+
+// BeginTx は ent の Client.BeginTx から呼び出されます
+// トランザクションのスパンは Commit または Rollback まで継続し、その中のクエリはトランザクションのスパンの子になります
+func (d *TracingDriver) BeginTx(ctx context.Context, opts *sql.TxOptions) (dialect.Tx, error) {
+	ctx, span := d.tracer.Start(ctx, "TRANSACTION",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system.name", d.Dialect())),
+	)
+
+	var tx dialect.Tx
+	var err error
+	if drv, ok := d.Driver.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (dialect.Tx, error)
+	}); ok {
+		tx, err = drv.BeginTx(ctx, opts)
+	} else {
+		tx, err = d.Driver.Tx(ctx)
+	}
+	if err != nil {
+		endSpan(span, err)
+		return nil, eris.Wrap(err, "")
+	}
+
+	return &tracingTx{
+		Tx:      tx,
+		tracer:  d.tracer,
+		dialect: d.Dialect(),
+		span:    span,
+	}, nil
+}
+
+// tracingTx は トランザクション内のクエリとトランザクションの終了をトレースする dialect.Tx です
+type tracingTx struct {
+	dialect.Tx
+	tracer  trace.Tracer
+	dialect string
+	span    trace.Span
+}
+
+func (t *tracingTx) Exec(ctx context.Context, query string, args, v any) error {
+	return traceQuery(t.queryContext(ctx), t.tracer, t.dialect, query, func(ctx context.Context) error {
+		return t.Tx.Exec(ctx, query, args, v)
+	})
+}
+
+func (t *tracingTx) Query(ctx context.Context, query string, args, v any) error {
+	return traceQuery(t.queryContext(ctx), t.tracer, t.dialect, query, func(ctx context.Context) error {
+		return t.Tx.Query(ctx, query, args, v)
+	})
+}
+
+// ExecContext は ent の Client.ExecContext から呼び出されます（SAVEPOINT の発行などに使用します）
+func (t *tracingTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	if err := t.Exec(ctx, query, args, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *tracingTx) Commit() error {
+	err := t.Tx.Commit()
+	t.span.SetAttributes(attribute.String("db.transaction.result", "commit"))
+	endSpan(t.span, err)
+	return err
+}
+
+func (t *tracingTx) Rollback() error {
+	err := t.Tx.Rollback()
+	t.span.SetAttributes(attribute.String("db.transaction.result", "rollback"))
+	endSpan(t.span, err)
+	return err
+}
+
+// queryContext は クエリのスパンの親をトランザクションのスパンにします
+// 呼び出し元のコンテキストのキャンセルなどはそのまま引き継ぎます
+func (t *tracingTx) queryContext(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, t.span)
+}
+
+func traceQuery(ctx context.Context, tracer trace.Tracer, dialectName string, query string, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, queryOperation(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", dialectName),
+			attribute.String("db.query.text", query),
+		),
+	)
+	err := fn(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// queryOperation は SQL文の先頭のキーワード（SELECT, INSERT など）を返します
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,89 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/infrastructure/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeDriver は 実行したクエリを記録する dialect.Driver です
+type fakeDriver struct {
+	queries []string
+	err     error
+}
+
+func (d *fakeDriver) Exec(ctx context.Context, query string, args, v any) error {
+	d.queries = append(d.queries, query)
+	return d.err
+}
+
+func (d *fakeDriver) Query(ctx context.Context, query string, args, v any) error {
+	d.queries = append(d.queries, query)
+	return d.err
+}
+
+func (d *fakeDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	return dialect.NopTx(d), nil
+}
+
+func (d *fakeDriver) Close() error    { return nil }
+func (d *fakeDriver) Dialect() string { return dialect.MySQL }
+
+func TestTracingDriver(t *testing.T) {
+	newTestee := func(inner *fakeDriver) (dialect.Driver, *tracetest.SpanRecorder) {
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		return db.NewTracingDriver(inner, tp), recorder
+	}
+
+	t.Run("クエリごとにSQLの操作名のスパンを記録すること", func(t *testing.T) {
+		inner := &fakeDriver{}
+		testee, recorder := newTestee(inner)
+
+		err := testee.Query(context.Background(), "SELECT `id` FROM `products` WHERE `id` = ?", []any{1}, nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"SELECT `id` FROM `products` WHERE `id` = ?"}, inner.queries)
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "SELECT", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.system.name", "mysql"))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.query.text", "SELECT `id` FROM `products` WHERE `id` = ?"))
+	})
+
+	t.Run("トランザクション内のクエリはトランザクションのスパンの子になること", func(t *testing.T) {
+		testee, recorder := newTestee(&fakeDriver{})
+
+		tx, err := testee.Tx(context.Background())
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Exec(context.Background(), "INSERT INTO `products` (`id`) VALUES (?)", []any{1}, nil))
+		assert.NoError(t, tx.Commit())
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 2)
+		insert, transaction := spans[0], spans[1]
+		assert.Equal(t, "INSERT", insert.Name())
+		assert.Equal(t, "TRANSACTION", transaction.Name())
+		assert.Equal(t, transaction.SpanContext().SpanID(), insert.Parent().SpanID())
+		assert.Contains(t, transaction.Attributes(), attribute.String("db.transaction.result", "commit"))
+	})
+
+	t.Run("エラーの場合はスパンをエラーにすること", func(t *testing.T) {
+		testee, recorder := newTestee(&fakeDriver{err: eris.New("deadlock")})
+
+		err := testee.Exec(context.Background(), "UPDATE `products` SET `price` = ?", []any{1}, nil)
+		assert.Error(t, err)
+
+		span := recorder.Ended()[0]
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, "deadlock", span.Status().Description)
+	})
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+)
+
+const (
+	// maxTxAttempts は デッドロックなどで失敗したトランザクションを実行する最大回数です
+	maxTxAttempts = 3
+	// txRetryBaseDelay は トランザクションをやり直すまでの待ち時間の基準値です（やり直すたびに2倍にします）
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+const (
+	// mysqlErrLockDeadlock は デッドロックを検出してトランザクションがロールバックされたことを表すエラー番号です
+	mysqlErrLockDeadlock = 1213
+	// mysqlErrLockWaitTimeout は ロック待ちがタイムアウトしたことを表すエラー番号です
+	mysqlErrLockWaitTimeout = 1205
+)
+
+type savepointDepthKey struct{}
+
+// Transaction は fn をトランザクション内で実行します
+// トランザクション内のコンテキストで呼び出した場合は SAVEPOINT を使用して外側のトランザクションに参加します
+func (c Connector) Transaction(ctx context.Context, fn func(ctx context.Context, tx *ent.Client) error) error {
+	if tx, ok := db.TxClient(ctx); ok {
+		return savepoint(ctx, tx, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.transaction(ctx, fn)
+		if err == nil || attempt >= maxTxAttempts || !isRetryableTxError(err) {
+			return err
+		}
+
+		// 同時にやり直したトランザクションが再び競合しないよう、待ち時間にゆらぎを加える
+		delay := txRetryBaseDelay << (attempt - 1)
+		delay += time.Duration(rand.Int63n(int64(delay)))
+		select {
+		case <-ctx.Done():
+			return eris.Wrap(err, "transaction retry canceled")
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c Connector) transaction(ctx context.Context, fn func(ctx context.Context, tx *ent.Client) error) error {
+	tx, err := c.Client.Tx(ctx)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			tx.Rollback()
+			panic(v)
+		}
+	}()
+	txClient := tx.Client()
+	if err := fn(db.WithTxClient(ctx, txClient), txClient); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			err = fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+		}
+		return eris.Wrap(err, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return eris.Wrap(err, "committing transaction")
+	}
+	return nil
+}
+
+// savepoint は 外側のトランザクション内で SAVEPOINT を作成して fn を実行します
+// fn がエラーを返した場合は SAVEPOINT までロールバックし、外側のトランザクションは継続できる状態にします
+func savepoint(ctx context.Context, tx *ent.Client, fn func(ctx context.Context, tx *ent.Client) error) error {
+	depth, _ := ctx.Value(savepointDepthKey{}).(int)
+	depth++
+	ctx = context.WithValue(ctx, savepointDepthKey{}, depth)
+	name := fmt.Sprintf("sp_%d", depth)
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return eris.Wrap(err, "")
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(v)
+		}
+	}()
+	if err := fn(ctx, tx); err != nil {
+		if _, rerr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rerr != nil {
+			err = fmt.Errorf("%w: rolling back to savepoint: %v", err, rerr)
+		}
+		return eris.Wrap(err, "")
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return eris.Wrap(err, "")
+	}
+	return nil
+}
+
+// isRetryableTxError は トランザクションをやり直せば成功する可能性があるエラー（デッドロック・ロック待ちのタイムアウト）かどうかを返します
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !eris.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
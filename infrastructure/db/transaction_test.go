@@ -0,0 +1,155 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/go-sql-driver/mysql"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	domainDB "github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/infrastructure/db"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// txRecordingDriver は トランザクションの開始・終了と実行したSQLを記録する dialect.Driver です
+type txRecordingDriver struct {
+	fakeDriver
+	// commitErrs は Commit が順に返すエラーです
+	commitErrs []error
+}
+
+func (d *txRecordingDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	d.queries = append(d.queries, "BEGIN")
+	return &txRecordingTx{driver: d}, nil
+}
+
+type txRecordingTx struct {
+	driver *txRecordingDriver
+}
+
+func (t *txRecordingTx) Exec(ctx context.Context, query string, args, v any) error {
+	return t.driver.Exec(ctx, query, args, v)
+}
+
+func (t *txRecordingTx) Query(ctx context.Context, query string, args, v any) error {
+	return t.driver.Query(ctx, query, args, v)
+}
+
+func (t *txRecordingTx) Commit() error {
+	t.driver.queries = append(t.driver.queries, "COMMIT")
+	if len(t.driver.commitErrs) == 0 {
+		return nil
+	}
+	err := t.driver.commitErrs[0]
+	t.driver.commitErrs = t.driver.commitErrs[1:]
+	return err
+}
+
+func (t *txRecordingTx) Rollback() error {
+	t.driver.queries = append(t.driver.queries, "ROLLBACK")
+	return nil
+}
+
+func newTxTestConnector(drv *txRecordingDriver) db.Connector {
+	client := ent.NewClient(ent.Driver(db.NewTracingDriver(drv, noop.NewTracerProvider())))
+	return db.Connector{Client: client, ReplicaClient: client}
+}
+
+func TestConnector_Transaction(t *testing.T) {
+	t.Run("トランザクションのクライアントをコンテキストから取得できること", func(t *testing.T) {
+		testee := newTxTestConnector(&txRecordingDriver{})
+
+		err := testee.Transaction(context.Background(), func(ctx context.Context, tx *ent.Client) error {
+			client, ok := domainDB.TxClient(ctx)
+			assert.True(t, ok)
+			assert.Same(t, tx, client)
+			assert.Same(t, tx, domainDB.ReadEnt(ctx, testee))
+			return nil
+		})
+
+		assert.NoError(t, err)
+		_, ok := domainDB.TxClient(context.Background())
+		assert.False(t, ok)
+	})
+
+	t.Run("入れ子のトランザクションはSAVEPOINTで外側のトランザクションに参加すること", func(t *testing.T) {
+		drv := &txRecordingDriver{}
+		testee := newTxTestConnector(drv)
+
+		err := testee.Transaction(context.Background(), func(ctx context.Context, outer *ent.Client) error {
+			// 失敗した入れ子のトランザクションは SAVEPOINT までロールバックし、外側は継続する
+			innerErr := testee.Transaction(ctx, func(ctx context.Context, inner *ent.Client) error {
+				assert.Same(t, outer, inner)
+				return eris.New("inner failed")
+			})
+			assert.Error(t, innerErr)
+
+			return testee.Transaction(ctx, func(ctx context.Context, inner *ent.Client) error {
+				return testee.Transaction(ctx, func(ctx context.Context, inner *ent.Client) error {
+					return nil
+				})
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"BEGIN",
+			"SAVEPOINT sp_1",
+			"ROLLBACK TO SAVEPOINT sp_1",
+			"SAVEPOINT sp_1",
+			"SAVEPOINT sp_2",
+			"RELEASE SAVEPOINT sp_2",
+			"RELEASE SAVEPOINT sp_1",
+			"COMMIT",
+		}, drv.queries)
+	})
+
+	t.Run("デッドロックで失敗した場合はトランザクションをやり直すこと", func(t *testing.T) {
+		drv := &txRecordingDriver{commitErrs: []error{&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}}}
+		testee := newTxTestConnector(drv)
+
+		calls := 0
+		err := testee.Transaction(context.Background(), func(ctx context.Context, tx *ent.Client) error {
+			calls++
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, []string{"BEGIN", "COMMIT", "BEGIN", "COMMIT"}, drv.queries)
+	})
+
+	t.Run("ロック待ちのタイムアウトが続く場合は最大回数でやり直しをやめること", func(t *testing.T) {
+		lockWaitTimeout := &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+		testee := newTxTestConnector(&txRecordingDriver{})
+
+		calls := 0
+		err := testee.Transaction(context.Background(), func(ctx context.Context, tx *ent.Client) error {
+			calls++
+			return lockWaitTimeout
+		})
+
+		var mysqlErr *mysql.MySQLError
+		assert.True(t, eris.As(err, &mysqlErr))
+		assert.Equal(t, uint16(1205), mysqlErr.Number)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("デッドロック以外のエラーはやり直さないこと", func(t *testing.T) {
+		drv := &txRecordingDriver{}
+		testee := newTxTestConnector(drv)
+
+		calls := 0
+		err := testee.Transaction(context.Background(), func(ctx context.Context, tx *ent.Client) error {
+			calls++
+			return &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, drv.queries)
+	})
+}
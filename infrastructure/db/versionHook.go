@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql"
+	domainDB "github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/hook"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// versionedMutation は VersionMixin を持つエンティティのミューテーションです
+type versionedMutation interface {
+	ent.Mutation
+	Version() (int64, bool)
+	SetVersion(int64)
+	AddVersion(int64)
+	OldVersion(ctx context.Context) (int64, error)
+	WhereP(ps ...func(*sql.Selector))
+}
+
+// useVersionHooks は VersionMixin を持つエンティティに楽観的排他制御のフックを登録します
+func useVersionHooks(client *ent.Client) {
+	client.Product.Use(hook.On(versionHook("product"), ent.OpUpdateOne|ent.OpUpdate))
+	client.Tenant.Use(hook.On(versionHook("tenant"), ent.OpUpdateOne|ent.OpUpdate))
+}
+
+// versionHook は 更新時に version を検証してインクリメントするフックです。
+// 1件の更新で SetVersion に取得時の version が指定された場合は WHERE version = ? を付与し、
+// 一致しない場合は types.ConflictError を返します。
+// version が指定されなかった場合は検証せずにインクリメントのみ行います。
+// db.WithExplicitVersion のコンテキストでは検証もインクリメントも行いません。
+func versionHook(label string) ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			vm, ok := m.(versionedMutation)
+			if !ok || domainDB.IsExplicitVersion(ctx) {
+				return next.Mutate(ctx, m)
+			}
+
+			expected, ok := vm.Version()
+			if !ok || !m.Op().Is(ent.OpUpdateOne) {
+				vm.AddVersion(1)
+				return next.Mutate(ctx, m)
+			}
+
+			current, err := vm.OldVersion(ctx)
+			if err != nil {
+				// 存在しない場合は ent.NotFoundError をそのまま返す
+				return nil, err
+			}
+			if current != expected {
+				return nil, types.NewConflictError(fmt.Sprintf("%s was modified by another request", label), current)
+			}
+
+			vm.SetVersion(expected + 1)
+			vm.WhereP(sql.FieldEQ("version", expected))
+			v, err := next.Mutate(ctx, m)
+			if ent.IsNotFound(err) {
+				// 上の確認の後に他のリクエストが更新した場合。最新の version は不明
+				return nil, types.NewConflictError(fmt.Sprintf("%s was modified by another request", label), 0)
+			}
+			return v, err
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package system
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// ErrInvalidToken は トークンの形式・署名・クレームが不正な場合のエラーです
+var ErrInvalidToken = eris.New("invalid token")
+
+// JwtAuthenticator は HS256 または RS256 で署名された JWT を検証します
+// 鍵は設定（JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY）またはローカルの JWKS ファイル（JWT_JWKS_FILE）から読み込みます
+type JwtAuthenticator struct {
+	timer      system.ITimer
+	hmacSecret []byte
+	// rsaKeys は kid ごとの RSA 公開鍵です。kid の無い鍵は空文字をキーにします
+	rsaKeys  map[string]*rsa.PublicKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	TenantIDs []string        `json:"tenant_ids"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// NewJwtAuthenticator は 設定から鍵を読み込んで JwtAuthenticator を生成します
+// 鍵が1つも設定されていない場合はエラーを返します
+func NewJwtAuthenticator(cfg *config.Config, timer system.ITimer) (system.IAuthenticator, error) {
+	a := &JwtAuthenticator{
+		timer:    timer,
+		rsaKeys:  map[string]*rsa.PublicKey{},
+		issuer:   cfg.Auth.Issuer,
+		audience: cfg.Auth.Audience,
+		leeway:   30 * time.Second,
+	}
+
+	if cfg.Auth.HS256Secret != "" {
+		a.hmacSecret = []byte(cfg.Auth.HS256Secret)
+	}
+
+	if cfg.Auth.RS256PublicKey != "" {
+		key, err := parseRSAPublicKeyPEM([]byte(cfg.Auth.RS256PublicKey))
+		if err != nil {
+			return nil, eris.Wrap(err, "JWT_RS256_PUBLIC_KEY")
+		}
+		a.rsaKeys[""] = key
+	}
+
+	if cfg.Auth.JWKSFile != "" {
+		keys, err := loadJWKSFile(cfg.Auth.JWKSFile)
+		if err != nil {
+			return nil, eris.Wrap(err, "JWT_JWKS_FILE")
+		}
+		for kid, key := range keys {
+			a.rsaKeys[kid] = key
+		}
+	}
+
+	if a.hmacSecret == nil && len(a.rsaKeys) == 0 {
+		return nil, eris.New("JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY or JWT_JWKS_FILE is required")
+	}
+
+	return a, nil
+}
+
+// Authenticate は トークンの署名と有効期限などのクレームを検証して主体を返します
+func (a *JwtAuthenticator) Authenticate(token string) (*auth.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, eris.Wrap(ErrInvalidToken, "malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, eris.Wrap(ErrInvalidToken, "malformed header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, eris.Wrap(ErrInvalidToken, "malformed signature")
+	}
+
+	if err := a.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, eris.Wrap(ErrInvalidToken, "malformed claims")
+	}
+
+	if err := a.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return toPrincipal(claims)
+}
+
+// verifySignature は alg に対応する鍵で署名を検証します
+// 設定されていない方式（none を含む）のトークンは拒否します
+func (a *JwtAuthenticator) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if a.hmacSecret == nil {
+			return eris.Wrap(ErrInvalidToken, "HS256 is not enabled")
+		}
+		mac := hmac.New(sha256.New, a.hmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return eris.Wrap(ErrInvalidToken, "signature mismatch")
+		}
+		return nil
+	case "RS256":
+		key, ok := a.rsaKeys[header.Kid]
+		if !ok {
+			return eris.Wrapf(ErrInvalidToken, "unknown key id: %s", header.Kid)
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return eris.Wrap(ErrInvalidToken, "signature mismatch")
+		}
+		return nil
+	default:
+		return eris.Wrapf(ErrInvalidToken, "unsupported alg: %s", header.Alg)
+	}
+}
+
+func (a *JwtAuthenticator) verifyClaims(claims jwtClaims) error {
+	now := a.timer.Now()
+
+	if claims.ExpiresAt == nil {
+		return eris.Wrap(ErrInvalidToken, "exp is required")
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(a.leeway)) {
+		return eris.Wrap(ErrInvalidToken, "token is expired")
+	}
+	if claims.NotBefore != nil && now.Add(a.leeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return eris.Wrap(ErrInvalidToken, "token is not valid yet")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return eris.Wrap(ErrInvalidToken, "issuer mismatch")
+	}
+	if a.audience != "" && !containsAudience(claims.Audience, a.audience) {
+		return eris.Wrap(ErrInvalidToken, "audience mismatch")
+	}
+
+	return nil
+}
+
+func toPrincipal(claims jwtClaims) (*auth.Principal, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, eris.Wrap(ErrInvalidToken, "sub must be a UUID")
+	}
+
+	tenantIDs := make([]uuid.UUID, 0, len(claims.TenantIDs))
+	for _, raw := range claims.TenantIDs {
+		tenantID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, eris.Wrap(ErrInvalidToken, "tenant_ids must be UUIDs")
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	return &auth.Principal{
+		UserID:    userID,
+		TenantIDs: tenantIDs,
+	}, nil
+}
+
+// containsAudience は aud クレーム（文字列または文字列の配列）に指定した値が含まれるかどうかを返します
+func containsAudience(raw json.RawMessage, audience string) bool {
+	if len(raw) == 0 {
+		return false
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err != nil {
+		return false
+	}
+	for _, aud := range multiple {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+	return eris.Wrap(json.Unmarshal(b, v), "")
+}
+
+func parseRSAPublicKeyPEM(b []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, eris.New("PEM block is not found")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, eris.New("public key is not RSA")
+	}
+	return key, nil
+}
+
+// loadJWKSFile は JWKS ファイルから RS256 で使用できる RSA 公開鍵を kid ごとに読み込みます
+func loadJWKSFile(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	var set jwks
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Alg != "" && k.Alg != "RS256") || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid n: kid=%s", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid e: kid=%s", k.Kid)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, eris.New("no RS256 key is found")
+	}
+	return keys, nil
+}
@@ -0,0 +1,159 @@
+package system_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+	systemInterface "github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJwtAuthenticator(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	userID := "00000000-0000-0000-0000-000000000001"
+	tenantID := "00000000-0000-0000-0000-000000000011"
+
+	newTimer := func(t *testing.T) systemInterface.ITimer {
+		timer := systemInterface.NewMockITimer(gomock.NewController(t))
+		timer.EXPECT().Now().Return(now).AnyTimes()
+		return timer
+	}
+
+	newConfig := func(auth config.Auth) *config.Config {
+		return &config.Config{Auth: auth}
+	}
+
+	validClaims := map[string]interface{}{
+		"sub":        userID,
+		"tenant_ids": []string{tenantID},
+		"exp":        now.Add(time.Hour).Unix(),
+	}
+
+	t.Run("HS256で署名されたトークンから主体を取得できること", func(t *testing.T) {
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{HS256Secret: "secret"}), newTimer(t))
+		assert.NoError(t, err)
+
+		principal, err := testee.Authenticate(signHS256(t, "secret", validClaims))
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.MustParse(userID), principal.UserID)
+		assert.Equal(t, []uuid.UUID{uuid.MustParse(tenantID)}, principal.TenantIDs)
+	})
+
+	t.Run("署名が一致しない場合はエラーになること", func(t *testing.T) {
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{HS256Secret: "secret"}), newTimer(t))
+		assert.NoError(t, err)
+
+		_, err = testee.Authenticate(signHS256(t, "other-secret", validClaims))
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+	})
+
+	t.Run("有効期限切れのトークンはエラーになること", func(t *testing.T) {
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{HS256Secret: "secret"}), newTimer(t))
+		assert.NoError(t, err)
+
+		_, err = testee.Authenticate(signHS256(t, "secret", map[string]interface{}{
+			"sub": userID,
+			"exp": now.Add(-time.Hour).Unix(),
+		}))
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+	})
+
+	t.Run("alg が none のトークンはエラーになること", func(t *testing.T) {
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{HS256Secret: "secret"}), newTimer(t))
+		assert.NoError(t, err)
+
+		token := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims) + "."
+		_, err = testee.Authenticate(token)
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+	})
+
+	t.Run("aud が一致しない場合はエラーになること", func(t *testing.T) {
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{HS256Secret: "secret", Audience: "cqrs-example"}), newTimer(t))
+		assert.NoError(t, err)
+
+		claims := map[string]interface{}{
+			"sub": userID,
+			"aud": []string{"other"},
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		_, err = testee.Authenticate(signHS256(t, "secret", claims))
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+
+		claims["aud"] = "cqrs-example"
+		_, err = testee.Authenticate(signHS256(t, "secret", claims))
+		assert.NoError(t, err)
+	})
+
+	t.Run("JWKSファイルの鍵でRS256のトークンを検証できること", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+		jwks, err := json.Marshal(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(jwksPath, jwks, 0o600))
+
+		testee, err := system.NewJwtAuthenticator(newConfig(config.Auth{JWKSFile: jwksPath}), newTimer(t))
+		assert.NoError(t, err)
+
+		principal, err := testee.Authenticate(signRS256(t, key, "key-1", validClaims))
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.MustParse(userID), principal.UserID)
+
+		_, err = testee.Authenticate(signRS256(t, key, "unknown", validClaims))
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+
+		// RS256 のみ設定されている場合は HS256 のトークンを受け付けない
+		_, err = testee.Authenticate(signHS256(t, "secret", validClaims))
+		assert.True(t, eris.Is(err, system.ErrInvalidToken))
+	})
+
+	t.Run("鍵が設定されていない場合は生成できないこと", func(t *testing.T) {
+		_, err := system.NewJwtAuthenticator(newConfig(config.Auth{}), newTimer(t))
+		assert.Error(t, err)
+	})
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
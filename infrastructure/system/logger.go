@@ -1,17 +1,22 @@
 package system
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-http-utils/headers"
 	"github.com/rotisserie/eris"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
 	systemInterface "github.com/t-kuni/cqrs-example/domain/infrastructure/system"
 	"io"
 	"net"
 	"net/http"
 	"os"
 	"runtime"
+	"syscall"
 	"time"
 )
 
@@ -24,11 +29,15 @@ type (
 
 	Logger struct {
 		logger *logrus.Logger
+		// fields は WithFields で付与された、全てのログに出力するフィールドです
+		fields logrus.Fields
+		// environment は HTTPリクエストのログに出力する実行環境です
+		environment string
 	}
 )
 
 // NewLogger は新しいロガーインスタンスを生成します
-func NewLogger() (systemInterface.ILogger, error) {
+func NewLogger(cfg *config.Config) (systemInterface.ILogger, error) {
 	logger := logrus.New()
 
 	// ロガーの設定
@@ -39,15 +48,11 @@ func NewLogger() (systemInterface.ILogger, error) {
 	})
 	logger.SetOutput(os.Stdout)
 
-	// ログレベルの設定
-	level, err := getLogLevel()
-	if err != nil {
-		return nil, eris.Wrap(err, "")
-	}
-	logger.SetLevel(level)
+	logger.SetLevel(cfg.Log.Level)
 
 	return &Logger{
-		logger: logger,
+		logger:      logger,
+		environment: cfg.App.Env,
 	}, nil
 }
 
@@ -67,7 +72,8 @@ func NewTestLogger() (systemInterface.ILogger, *test.Hook) {
 	testLogger.SetOutput(io.Discard)
 
 	return &Logger{
-		logger: testLogger,
+		logger:      testLogger,
+		environment: "test",
 	}, loggerHook
 }
 
@@ -79,84 +85,105 @@ func NewLoggerWithCustomLogger(customLogger *logrus.Logger) systemInterface.ILog
 	}
 }
 
+// WithFields は 全てのログに指定したフィールドを付与するロガーを返します
+// バッチ処理の実行IDなど、リクエストに紐付かない相関IDを付与するのに使用します
+func (l *Logger) WithFields(fields map[string]interface{}) systemInterface.ILogger {
+	merged := logrus.Fields{}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{
+		logger:      l.logger,
+		fields:      merged,
+		environment: l.environment,
+	}
+}
+
+func (l *Logger) entry() *logrus.Entry {
+	return l.logger.WithFields(l.fields)
+}
+
 func (l *Logger) Info(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		Info(msg)
 }
 
 func (l *Logger) SimpleInfoF(format string, args ...interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, nil)).
 		Infof(format, args...)
 }
 
 func (l *Logger) Warn(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		Warn(msg)
 }
 
 func (l *Logger) WarnWithError(req *http.Request, e error, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("panic", false).
 		Warnf("%+v", e)
 }
 
 func (l *Logger) Error(req *http.Request, e error, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("panic", false).
 		Errorf("%+v", e)
 }
 
 func (l *Logger) Debug(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		Debug(msg)
 }
 
 func (l *Logger) Fatal(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		Fatal(msg)
 }
 
 func (l *Logger) SimpleFatal(e error, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
 		Fatalf("%+v", e)
 }
 
 func (l *Logger) Panic(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("panic", true).
 		Error(msg)
 }
 
 func (l *Logger) PanicV2(req *http.Request, msg string, params map[string]interface{}) {
 	stackInfo := makeStackInfo(runtime.Caller(1))
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, params)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("panic", true).
 		Error(msg)
 }
@@ -168,9 +195,9 @@ func (l *Logger) RequestLog(req *http.Request) {
 	method := req.Method
 	msg := fmt.Sprintf("[Request][%s]%s", url, method)
 
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, nil)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		Info(msg)
 }
 
@@ -181,9 +208,9 @@ func (l *Logger) RequestLogV2(req *http.Request, reqBody map[string]interface{})
 	method := req.Method
 	msg := fmt.Sprintf("[Request][%s]%s", url, method)
 
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, nil)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("input", reqBody).
 		Info(msg)
 }
@@ -195,9 +222,9 @@ func (l *Logger) ResponseLog(req *http.Request, status int, latency time.Duratio
 	method := req.Method
 	msg := fmt.Sprintf("[Response][%s]%s", url, method)
 
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, nil)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("latency", latency).
 		WithField("latency_human", latencyHuman).
 		WithField("http_status", status).
@@ -211,28 +238,26 @@ func (l *Logger) ResponseLogV2(req *http.Request, status int, latency time.Durat
 	method := req.Method
 	msg := fmt.Sprintf("[Response][%s]%s", url, method)
 
-	l.logger.
+	l.entry().
 		WithFields(makeCommonFields(stackInfo, nil)).
-		WithFields(makeHttpFieldsV2(req)).
+		WithFields(makeHttpFieldsV2(req, l.environment)).
 		WithField("latency", latency).
 		WithField("latency_human", latencyHuman).
 		WithField("http_status", status).
 		Info(msg)
 }
 
-func getLogLevel() (logrus.Level, error) {
-	levelStr := os.Getenv("LOG_LEVEL")
-
-	if levelStr == "" {
-		return logrus.InfoLevel, nil
+// Flush は 出力先がファイルの場合に、出力済みのログをディスクに書き出します
+// パイプや端末など同期できない出力先の場合は何もしません
+func (l *Logger) Flush() error {
+	syncer, ok := l.logger.Out.(interface{ Sync() error })
+	if !ok {
+		return nil
 	}
-
-	level, err := logrus.ParseLevel(levelStr)
-	if err != nil {
-		return 0, eris.Wrap(err, "")
+	if err := syncer.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return eris.Wrap(err, "")
 	}
-
-	return level, nil
+	return nil
 }
 
 func makeCommonFields(stackInfo *StackInfo, params map[string]interface{}) map[string]interface{} {
@@ -256,20 +281,33 @@ func makeCommonFields(stackInfo *StackInfo, params map[string]interface{}) map[s
 	}
 }
 
-func makeHttpFieldsV2(req *http.Request) map[string]interface{} {
+func makeHttpFieldsV2(req *http.Request, environment string) map[string]interface{} {
 	if req == nil {
 		return nil
 	}
 
-	return map[string]interface{}{
+	fields := map[string]interface{}{
 		"uri":         req.RequestURI,
 		"ip":          req.Header.Get(headers.XForwardedFor),
 		"http_method": req.Method,
 		"server_ip":   getLocalIP(),
 		"referrer":    req.Referer(),
-		"environment": os.Getenv("APP_ENV"),
+		"environment": environment,
 		"header":      makeHeaderFieldV2(req),
 	}
+
+	// リクエストIDとトレースコンテキストを出力する
+	for k, v := range correlation.Fields(req.Context()) {
+		fields[k] = v
+	}
+
+	// 認証済みのリクエストは主体を出力する
+	if principal, ok := auth.FromContext(req.Context()); ok {
+		fields["user_id"] = principal.UserID.String()
+		fields["tenant_ids"] = principal.TenantIDs
+	}
+
+	return fields
 }
 
 func makeHeaderFieldV2(req *http.Request) map[string]interface{} {
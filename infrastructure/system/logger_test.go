@@ -3,8 +3,11 @@ package system_test
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/rotisserie/eris"
 	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
 	"github.com/t-kuni/cqrs-example/infrastructure/system"
 	"net/http"
 	"net/http/httptest"
@@ -126,4 +129,53 @@ func TestLogger(t *testing.T) {
 		assert.Regexp(t, "logger_test.go:[0-9]+", log["message"])
 		assert.Equal(t, "warning", log["level"])
 	})
+	t.Run("Should output principal of authenticated request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/test-path", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{
+			UserID:    uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			TenantIDs: []uuid.UUID{uuid.MustParse("00000000-0000-0000-0000-000000000011")},
+		}))
+
+		logger, loggerHook := system.NewTestLogger()
+		logger.Info(req, "test message", nil)
+
+		logStr, err := loggerHook.LastEntry().String()
+		assert.NoError(t, err)
+
+		var log map[string]interface{}
+		err = json.Unmarshal([]byte(logStr), &log)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "00000000-0000-0000-0000-000000000001", log["user_id"])
+		assert.Equal(t, []interface{}{"00000000-0000-0000-0000-000000000011"}, log["tenant_ids"])
+	})
+
+	t.Run("Should output request id and trace context", func(t *testing.T) {
+		traceContext := correlation.NewTraceContext(nil)
+		req := httptest.NewRequest(http.MethodGet, "/test-path", nil)
+		ctx := correlation.WithRequestID(req.Context(), "req-1")
+		req = req.WithContext(correlation.WithTraceContext(ctx, traceContext))
+
+		logger, loggerHook := system.NewTestLogger()
+		logger.Warn(req, "test message", nil)
+
+		data := loggerHook.LastEntry().Data
+		assert.Equal(t, "req-1", data["request_id"])
+		assert.Equal(t, traceContext.TraceID, data["trace_id"])
+		assert.Equal(t, traceContext.SpanID, data["span_id"])
+	})
+
+	t.Run("Should output fields given by WithFields in every log", func(t *testing.T) {
+		logger, loggerHook := system.NewTestLogger()
+		runLogger := logger.WithFields(map[string]interface{}{"run_id": "run-1"})
+
+		runLogger.SimpleInfoF("progress %d", 1)
+		assert.Equal(t, "run-1", loggerHook.LastEntry().Data["run_id"])
+
+		runLogger.Info(httptest.NewRequest(http.MethodGet, "/test-path", nil), "test message", nil)
+		assert.Equal(t, "run-1", loggerHook.LastEntry().Data["run_id"])
+
+		logger.SimpleInfoF("without fields")
+		assert.Nil(t, loggerHook.LastEntry().Data["run_id"])
+	})
 }
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rotisserie/eris"
+	systemInterface "github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// Metrics は IMetrics の Prometheus による実装です
+type Metrics struct {
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	openSearchDuration  *prometheus.HistogramVec
+	openSearchErrors    *prometheus.CounterVec
+	projectionIndexed   prometheus.Counter
+	projectionFailures  prometheus.Counter
+	projectionLag       prometheus.Gauge
+}
+
+// NewMetricsRegistry は /metrics で公開するメトリクスのレジストリを生成します
+// Go ランタイムとプロセスのメトリクスを含みます
+func NewMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// NewMetrics は メトリクスを生成してレジストリに登録します
+func NewMetrics(registry *prometheus.Registry) (systemInterface.IMetrics, error) {
+	m := &Metrics{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of HTTP requests by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		openSearchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "opensearch_request_duration_seconds",
+			Help:    "Latency of OpenSearch requests by operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation"}),
+		openSearchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "opensearch_request_errors_total",
+			Help: "Number of failed OpenSearch requests by operation.",
+		}, []string{"operation"}),
+		projectionIndexed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "projection_documents_indexed_total",
+			Help: "Number of products indexed into OpenSearch.",
+		}),
+		projectionFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "projection_failures_total",
+			Help: "Number of products that failed to be indexed into OpenSearch.",
+		}),
+		projectionLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "projection_lag_documents",
+			Help: "Number of products not yet indexed into OpenSearch by the running transfer.",
+		}),
+	}
+
+	collectorsToRegister := []prometheus.Collector{
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.openSearchDuration,
+		m.openSearchErrors,
+		m.projectionIndexed,
+		m.projectionFailures,
+		m.projectionLag,
+	}
+	for _, c := range collectorsToRegister {
+		if err := registry.Register(c); err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) ObserveHTTPRequest(method string, route string, status int, latency time.Duration) {
+	labels := prometheus.Labels{
+		"method": method,
+		"route":  route,
+		"status": strconv.Itoa(status),
+	}
+	m.httpRequests.With(labels).Inc()
+	m.httpRequestDuration.With(labels).Observe(latency.Seconds())
+}
+
+func (m *Metrics) ObserveOpenSearchRequest(operation string, latency time.Duration, err error) {
+	m.openSearchDuration.WithLabelValues(operation).Observe(latency.Seconds())
+	if err != nil {
+		m.openSearchErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (m *Metrics) IncProjectionIndexed() {
+	m.projectionIndexed.Inc()
+}
+
+func (m *Metrics) IncProjectionFailures() {
+	m.projectionFailures.Inc()
+}
+
+func (m *Metrics) SetProjectionLag(documents int) {
+	m.projectionLag.Set(float64(documents))
+}
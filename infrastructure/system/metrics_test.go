@@ -0,0 +1,75 @@
+package system_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("HTTPリクエストの件数をメソッド・ルート・ステータスごとに記録すること", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		metrics, err := system.NewMetrics(registry)
+		assert.NoError(t, err)
+
+		metrics.ObserveHTTPRequest("GET", "/products/{id}", 200, 10*time.Millisecond)
+		metrics.ObserveHTTPRequest("GET", "/products/{id}", 200, 20*time.Millisecond)
+		metrics.ObserveHTTPRequest("GET", "/products/{id}", 404, 5*time.Millisecond)
+
+		expected := `
+# HELP http_requests_total Number of HTTP requests by method, route and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/products/{id}",status="200"} 2
+http_requests_total{method="GET",route="/products/{id}",status="404"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"))
+		assert.Equal(t, 2, testutil.CollectAndCount(registry, "http_request_duration_seconds"))
+	})
+
+	t.Run("OpenSearchのエラーの件数を操作ごとに記録すること", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		metrics, err := system.NewMetrics(registry)
+		assert.NoError(t, err)
+
+		metrics.ObserveOpenSearchRequest("index", time.Millisecond, nil)
+		metrics.ObserveOpenSearchRequest("index", time.Millisecond, eris.New("failed"))
+
+		expected := `
+# HELP opensearch_request_errors_total Number of failed OpenSearch requests by operation.
+# TYPE opensearch_request_errors_total counter
+opensearch_request_errors_total{operation="index"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "opensearch_request_errors_total"))
+	})
+
+	t.Run("同期の件数と未同期の件数を記録すること", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		metrics, err := system.NewMetrics(registry)
+		assert.NoError(t, err)
+
+		metrics.IncProjectionIndexed()
+		metrics.IncProjectionIndexed()
+		metrics.IncProjectionFailures()
+		metrics.SetProjectionLag(8)
+
+		expected := `
+# HELP projection_documents_indexed_total Number of products indexed into OpenSearch.
+# TYPE projection_documents_indexed_total counter
+projection_documents_indexed_total 2
+# HELP projection_failures_total Number of products that failed to be indexed into OpenSearch.
+# TYPE projection_failures_total counter
+projection_failures_total 1
+# HELP projection_lag_documents Number of products not yet indexed into OpenSearch by the running transfer.
+# TYPE projection_lag_documents gauge
+projection_lag_documents 8
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"projection_documents_indexed_total", "projection_failures_total", "projection_lag_documents"))
+	})
+}
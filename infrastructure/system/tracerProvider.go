@@ -0,0 +1,65 @@
+package system
+
+import (
+	"context"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// NewTracerProvider は 設定（OTEL_TRACES_EXPORTER）で指定したエクスポータにスパンを出力する TracerProvider を生成します
+//   - otlp: OTLP/HTTP で送信します（送信先は OTEL_EXPORTER_OTLP_ENDPOINT などの標準の環境変数で指定）
+//   - stdout: 標準出力に出力します
+//   - 未指定・none: スパンは生成しますがエクスポートしません（ログのトレースIDには使用されます）
+//
+// サービス名は OTEL_SERVICE_NAME（未指定の場合は cqrs-example）、サンプリングは OTEL_TRACES_SAMPLER で指定します
+// .env の標準の環境変数もエクスポータから参照できるよう、設定の読み込み後に生成します
+// アプリケーションの停止時に未送信のスパンを送信します
+func NewTracerProvider(lc fx.Lifecycle, cfg *config.Config) (trace.TracerProvider, error) {
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", cfg.Tracing.ServiceName)),
+	)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+	}
+
+	switch cfg.Tracing.Exporter {
+	case config.TracesExporterNone:
+	case config.TracesExporterOtlp:
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case config.TracesExporterStdout:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, eris.Errorf("unsupported OTEL_TRACES_EXPORTER: %s", cfg.Tracing.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return tp.Shutdown(ctx)
+		},
+	})
+
+	return tp, nil
+}
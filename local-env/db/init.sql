@@ -1 +1,2 @@
 CREATE DATABASE IF NOT EXISTS example_test;
+CREATE DATABASE IF NOT EXISTS example_dev;
@@ -11,14 +11,16 @@ import (
 	"time"
 )
 
-// AccessLog RequestログとResponseログを出力するミドルウェア
+// AccessLog RequestログとResponseログを出力し、リクエストの件数と処理時間をメトリクスに記録するミドルウェア
 type AccessLog struct {
-	logger system.ILogger
+	logger  system.ILogger
+	metrics system.IMetrics
 }
 
-func NewAccessLog(logger system.ILogger) (*AccessLog, error) {
+func NewAccessLog(logger system.ILogger, metrics system.IMetrics) (*AccessLog, error) {
 	return &AccessLog{
-		logger: logger,
+		logger:  logger,
+		metrics: metrics,
 	}, nil
 }
 
@@ -49,12 +51,21 @@ func (m AccessLog) AccessLog(next http.Handler) http.Handler {
 		reqBody := getRequestBody(r)
 		m.logger.RequestLogV2(r, reqBody)
 
+		ctx, route := withRouteRecorder(r.Context())
+		r = r.WithContext(ctx)
+
 		respWriter := NewCustomResponseWriter(w)
 		latency, latencyHuman := measureLatency(func() {
 			next.ServeHTTP(respWriter, r)
 		})
 
 		m.logger.ResponseLogV2(r, respWriter.StatusCode, latency, latencyHuman)
+
+		status := respWriter.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.metrics.ObserveHTTPRequest(r.Method, route.route(), status, latency)
 	})
 }
 
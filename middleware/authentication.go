@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+)
+
+// Authentication Authorization ヘッダの Bearer トークンを検証し、認証済みの主体をコンテキストに設定するミドルウェア
+// トークンが無い・不正な場合もリクエストは拒否せず、401 の応答は swagger のセキュリティ定義に任せます
+// セキュリティ定義の認証処理はトークンを再度検証せず、ここで設定した主体を使用します
+type Authentication struct {
+	logger        system.ILogger
+	authenticator system.IAuthenticator
+}
+
+func NewAuthentication(logger system.ILogger, authenticator system.IAuthenticator) (*Authentication, error) {
+	return &Authentication{
+		logger:        logger,
+		authenticator: authenticator,
+	}, nil
+}
+
+func (m Authentication) Authentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := BearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := m.authenticator.Authenticate(token)
+		if err != nil {
+			m.logger.WarnWithError(r, err, nil)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// BearerToken は Authorization ヘッダの値から Bearer トークンを取り出します
+func BearerToken(authorization string) (string, bool) {
+	const prefix = "bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
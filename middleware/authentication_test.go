@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/middleware"
+	"github.com/t-kuni/cqrs-example/testUtil"
+)
+
+func TestAuthentication(t *testing.T) {
+	principal := &auth.Principal{
+		UserID:    uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		TenantIDs: []uuid.UUID{uuid.MustParse("00000000-0000-0000-0000-000000000011")},
+	}
+
+	t.Run("Bearerトークンを検証して主体とテナントをコンテキストに設定すること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		authenticator := system.NewMockIAuthenticator(cont.MockCtrl)
+		authenticator.EXPECT().Authenticate("valid-token").Return(principal, nil)
+		testUtil.Override[system.IAuthenticator](cont, authenticator)
+
+		var authentication *middleware.Authentication
+		var tenantScope *middleware.TenantScope
+		cont.Exec(func(a *middleware.Authentication, s *middleware.TenantScope) {
+			authentication = a
+			tenantScope = s
+		})
+
+		var gotPrincipal *auth.Principal
+		var gotTenantID uuid.UUID
+		handler := authentication.Authentication(tenantScope.TenantScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPrincipal, _ = auth.FromContext(r.Context())
+			gotTenantID, _ = tenancy.FromContext(r.Context())
+		})))
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, principal, gotPrincipal)
+		assert.Equal(t, principal.TenantIDs[0], gotTenantID)
+	})
+
+	t.Run("不正なトークンの場合は主体を設定せずに処理を続けること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		authenticator := system.NewMockIAuthenticator(cont.MockCtrl)
+		authenticator.EXPECT().Authenticate("invalid-token").Return(nil, eris.New("invalid token"))
+		testUtil.Override[system.IAuthenticator](cont, authenticator)
+
+		var testee *middleware.Authentication
+		cont.Exec(func(a *middleware.Authentication) {
+			testee = a
+		})
+
+		called := false
+		authenticated := true
+		handler := testee.Authentication(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			_, authenticated = auth.FromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("Authorization", "Bearer invalid-token")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.True(t, called)
+		assert.False(t, authenticated)
+	})
+
+	t.Run("主体がアクセスできないテナントを指定した場合は403を返すこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		var testee *middleware.TenantScope
+		cont.Exec(func(s *middleware.TenantScope) {
+			testee = s
+		})
+
+		called := false
+		handler := testee.TenantScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set(middleware.TenantIDHeader, "00000000-0000-0000-0000-000000000012")
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.False(t, called)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/service"
+)
+
+// Drain 処理中のリクエストを数え、シャットダウン時にその完了を待つミドルウェア
+// プローブのリクエストは数えないよう、HealthCheck より内側に配置します
+type Drain struct {
+	healthCheckService service.IHealthCheckService
+	logger             system.ILogger
+	// timeout シャットダウン時に処理中のリクエストの完了を待つ時間
+	timeout time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	// idle 処理中のリクエストが無くなった時に close されるチャネル
+	idle chan struct{}
+}
+
+// NewDrain 処理中のリクエストの完了を待つ時間は設定の Server.ShutdownDrainTimeout です
+func NewDrain(cfg *config.Config, healthCheckService service.IHealthCheckService, logger system.ILogger) (*Drain, error) {
+	idle := make(chan struct{})
+	close(idle)
+
+	return &Drain{
+		healthCheckService: healthCheckService,
+		logger:             logger,
+		timeout:            cfg.Server.ShutdownDrainTimeout,
+		idle:               idle,
+	}, nil
+}
+
+func (m *Drain) Drain(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.begin()
+		defer m.end()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BeginShutdown readiness を down にし、処理中のリクエストの完了を待ちます
+// サーバがリスナを閉じる前（PreServerShutdown）に呼び出します
+// 待っている間もロードバランサから切り離されるまでに届いたリクエストは受け付け、その完了も待ちます
+func (m *Drain) BeginShutdown() {
+	m.healthCheckService.MarkNotReady()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	m.logger.SimpleInfoF("Draining in-flight requests: %d (timeout: %s)", m.InFlight(), m.timeout)
+	if err := m.Wait(ctx); err != nil {
+		m.logger.WarnWithError(nil, err, nil)
+		return
+	}
+	m.logger.SimpleInfoF("All in-flight requests completed")
+}
+
+// Wait 処理中のリクエストが無くなるまで待ちます
+// ctx の期限までに完了しない場合は、残っているリクエストの件数を含むエラーを返します
+func (m *Drain) Wait(ctx context.Context) error {
+	for {
+		m.mu.Lock()
+		idle := m.idle
+		m.mu.Unlock()
+
+		select {
+		case <-idle:
+			// 待っている間に新しいリクエストが届いた場合は、その完了も待つ
+			if m.InFlight() == 0 {
+				return nil
+			}
+		case <-ctx.Done():
+			return eris.Wrapf(ctx.Err(), "%d in-flight requests did not complete", m.InFlight())
+		}
+	}
+}
+
+// InFlight 処理中のリクエストの件数を返します
+func (m *Drain) InFlight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight
+}
+
+func (m *Drain) begin() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight == 0 {
+		m.idle = make(chan struct{})
+	}
+	m.inFlight++
+}
+
+func (m *Drain) end() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if m.inFlight == 0 {
+		close(m.idle)
+	}
+}
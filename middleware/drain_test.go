@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	infraSystem "github.com/t-kuni/cqrs-example/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/middleware"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDrain(t *testing.T) {
+	// prepare は release が close されるまで応答しないハンドラを持つサーバを起動します
+	prepare := func(t *testing.T, drainTimeout time.Duration, healthCheckService service.IHealthCheckService) (*middleware.Drain, *httptest.Server, chan struct{}) {
+		logger, _ := infraSystem.NewTestLogger()
+		cfg := &config.Config{Server: config.Server{ShutdownDrainTimeout: drainTimeout}}
+		testee, err := middleware.NewDrain(cfg, healthCheckService, logger)
+		assert.NoError(t, err)
+
+		release := make(chan struct{})
+		server := httptest.NewServer(testee.Drain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})))
+		t.Cleanup(server.Close)
+		return testee, server, release
+	}
+
+	// request はリクエストを送信し、ステータスコードをチャネルで返します
+	request := func(server *httptest.Server) chan int {
+		status := make(chan int, 1)
+		go func() {
+			res, err := http.Get(server.URL)
+			if err != nil {
+				status <- 0
+				return
+			}
+			res.Body.Close()
+			status <- res.StatusCode
+		}()
+		return status
+	}
+
+	t.Run("readinessをdownにしてから処理中のリクエストの完了を待つこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		healthCheckService := service.NewMockIHealthCheckService(ctrl)
+		healthCheckService.EXPECT().MarkNotReady()
+		testee, server, release := prepare(t, time.Minute, healthCheckService)
+
+		status := request(server)
+		assert.Eventually(t, func() bool { return testee.InFlight() == 1 }, time.Second, time.Millisecond)
+
+		shutdownDone := make(chan struct{})
+		go func() {
+			testee.BeginShutdown()
+			close(shutdownDone)
+		}()
+
+		select {
+		case <-shutdownDone:
+			t.Fatal("BeginShutdown returned before the in-flight request completed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+
+		select {
+		case <-shutdownDone:
+		case <-time.After(time.Second):
+			t.Fatal("BeginShutdown did not return after the in-flight request completed")
+		}
+		assert.Equal(t, http.StatusOK, <-status)
+		assert.Equal(t, 0, testee.InFlight())
+	})
+
+	t.Run("期限までに完了しない場合は残っているリクエストの件数を含むエラーを返すこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		testee, server, release := prepare(t, time.Minute, service.NewMockIHealthCheckService(ctrl))
+		defer close(release)
+
+		request(server)
+		assert.Eventually(t, func() bool { return testee.InFlight() == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := testee.Wait(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "1 in-flight requests did not complete")
+	})
+
+	t.Run("ShutdownDrainTimeoutまでに完了しない場合は待つのをやめること", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		healthCheckService := service.NewMockIHealthCheckService(ctrl)
+		healthCheckService.EXPECT().MarkNotReady()
+		testee, server, release := prepare(t, 50*time.Millisecond, healthCheckService)
+		defer close(release)
+
+		request(server)
+		assert.Eventually(t, func() bool { return testee.InFlight() == 1 }, time.Second, time.Millisecond)
+
+		start := time.Now()
+		testee.BeginShutdown()
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, 1, testee.InFlight())
+	})
+}
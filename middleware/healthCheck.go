@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/t-kuni/cqrs-example/domain/service"
+)
+
+const (
+	// LivenessPath liveness プローブのパス
+	LivenessPath = "/healthz"
+	// ReadinessPath readiness プローブのパス
+	ReadinessPath = "/readyz"
+)
+
+// HealthCheck /healthz と /readyz でヘルスチェックの結果をJSONで返すミドルウェア
+// down の場合は 503 を返します
+// プローブがアクセスログやトレースに残らないよう、Tracing より外側に配置します
+type HealthCheck struct {
+	healthCheckService service.IHealthCheckService
+}
+
+func NewHealthCheck(healthCheckService service.IHealthCheckService) (*HealthCheck, error) {
+	return &HealthCheck{
+		healthCheckService: healthCheckService,
+	}, nil
+}
+
+func (m HealthCheck) HealthCheck(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.URL.Path {
+		case LivenessPath:
+			writeHealthReport(w, m.healthCheckService.Liveness(r.Context()))
+		case ReadinessPath:
+			writeHealthReport(w, m.healthCheckService.Readiness(r.Context()))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func writeHealthReport(w http.ResponseWriter, report service.HealthReport) {
+	status := http.StatusOK
+	if !report.IsUp() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
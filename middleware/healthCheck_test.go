@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/service"
+	"github.com/t-kuni/cqrs-example/middleware"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthCheck(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	t.Run("readinessがdownの場合は503と依存先ごとの結果を返すこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		healthCheckService := service.NewMockIHealthCheckService(ctrl)
+		healthCheckService.EXPECT().Readiness(gomock.Any()).Return(service.HealthReport{
+			Status: service.HealthStatusDown,
+			Checks: map[string]service.HealthCheck{
+				"mysql":      {Status: service.HealthStatusDown, Error: "connection refused"},
+				"opensearch": {Status: service.HealthStatusUp},
+			},
+		})
+		testee, err := middleware.NewHealthCheck(healthCheckService)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		testee.HealthCheck(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"status": "down",
+			"checks": {
+				"mysql": {"status": "down", "error": "connection refused"},
+				"opensearch": {"status": "up"}
+			}
+		}`, rec.Body.String())
+	})
+
+	t.Run("livenessがupの場合は200を返すこと", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		healthCheckService := service.NewMockIHealthCheckService(ctrl)
+		healthCheckService.EXPECT().Liveness(gomock.Any()).Return(service.HealthReport{Status: service.HealthStatusUp})
+		testee, err := middleware.NewHealthCheck(healthCheckService)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		testee.HealthCheck(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "up", body["status"])
+	})
+
+	t.Run("プローブ以外のパスは次のハンドラに委ねること", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		testee, err := middleware.NewHealthCheck(service.NewMockIHealthCheckService(ctrl))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		testee.HealthCheck(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+	})
+}
@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/ent"
+	"github.com/t-kuni/cqrs-example/ent/idempotencykey"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+const (
+	// IdempotencyKeyHeader クライアントが再送を識別するために送信するヘッダ
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader 保存済みのレスポンスを返したことを示すヘッダ
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// IdempotencyKeyTTL キーとレスポンスを保持する期間
+	IdempotencyKeyTTL = 24 * time.Hour
+)
+
+// correlationHeaders リクエストごとに RequestID が設定するため、保存せず再生時にも上書きしないヘッダ
+var correlationHeaders = []string{RequestIDHeader, TraceparentHeader, "tracestate"}
+
+// Idempotency Idempotency-Key ヘッダが指定された書き込みリクエストを冪等にするミドルウェア
+// 同じ主体・テナントから同じキーで再送されたリクエストには、初回のレスポンスを再生して返します
+type Idempotency struct {
+	logger      system.ILogger
+	dbConnector db.IConnector
+	timer       system.ITimer
+}
+
+func NewIdempotency(logger system.ILogger, conn db.IConnector, timer system.ITimer) (*Idempotency, error) {
+	return &Idempotency{
+		logger:      logger,
+		dbConnector: conn,
+		timer:       timer,
+	}, nil
+}
+
+// captureResponseWriter クライアントに返すレスポンスを保存用に複製するResponseWriter
+type captureResponseWriter struct {
+	wrapped    http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *captureResponseWriter) Header() http.Header {
+	return w.wrapped.Header()
+}
+
+func (w *captureResponseWriter) Write(content []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	w.body.Write(content)
+	return w.wrapped.Write(content)
+}
+
+func (w *captureResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.wrapped.WriteHeader(statusCode)
+}
+
+func (m Idempotency) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isWriteMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// キーは主体ごとに保存するため、認証されていないリクエストは保存も再生もせずに処理する（401 は swagger のセキュリティ定義の認証処理が返す）
+		principal, authenticated := auth.FromContext(r.Context())
+		if !authenticated {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// テナントの範囲に制限されていない・テナントを解決できていない場合は uuid.Nil のテナントとして保存する
+		tenantID, _ := tenancy.FromContext(r.Context())
+		scope := idempotencyScope{principalID: principal.UserID, tenantID: tenantID, key: key}
+
+		ctx := r.Context()
+		client := m.dbConnector.GetEnt()
+
+		requestHash, err := hashRequest(r)
+		if err != nil {
+			m.logger.Error(r, eris.Wrap(err, ""), nil)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		stored, err := m.findValidKey(r, client, scope)
+		if err != nil {
+			m.logger.Error(r, eris.Wrap(err, ""), nil)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if stored != nil {
+			m.replay(w, stored, requestHash)
+			return
+		}
+
+		now := m.timer.Now()
+		created, err := client.IdempotencyKey.Create().
+			SetPrincipalID(scope.principalID).
+			SetTenantID(scope.tenantID).
+			SetKey(scope.key).
+			SetRequestHash(requestHash).
+			SetCreatedAt(now).
+			SetExpiresAt(now.Add(IdempotencyKeyTTL)).
+			Save(ctx)
+		if ent.IsConstraintError(err) {
+			// 同じキーのリクエストが同時に処理されている
+			writeIdempotencyError(w, http.StatusConflict, "a request with the same Idempotency-Key is being processed")
+			return
+		}
+		if err != nil {
+			m.logger.Error(r, eris.Wrap(err, ""), nil)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// 処理中（status_code が 0）のまま残ると有効期限まで再送が 409 になるため、
+		// 処理結果の保存とキーの解放はクライアントの切断でキャンセルされないコンテキストで行う
+		storeCtx := context.WithoutCancel(ctx)
+		release := func() {
+			if err := client.IdempotencyKey.DeleteOneID(created.ID).Exec(storeCtx); err != nil {
+				m.logger.Error(r, eris.Wrap(err, ""), nil)
+			}
+		}
+		// ハンドラがパニックした場合もキーを解放し、パニックは外側の Recover に任せる
+		defer func() {
+			if rec := recover(); rec != nil {
+				release()
+				panic(rec)
+			}
+		}()
+
+		respWriter := &captureResponseWriter{wrapped: w}
+		next.ServeHTTP(respWriter, r)
+
+		// サーバ側のエラーは再送で成功する可能性があるため保存せずにキーを解放する
+		if respWriter.statusCode == 0 || respWriter.statusCode >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		err = client.IdempotencyKey.UpdateOneID(created.ID).
+			SetStatusCode(respWriter.statusCode).
+			SetResponseHeader(storableHeader(w.Header())).
+			SetResponseBody(respWriter.body.Bytes()).
+			Exec(storeCtx)
+		if err != nil {
+			m.logger.Error(r, eris.Wrap(err, ""), nil)
+			release()
+		}
+	})
+}
+
+// idempotencyScope キーを一意に識別する主体・テナント・キーの組
+type idempotencyScope struct {
+	principalID uuid.UUID
+	tenantID    uuid.UUID
+	key         string
+}
+
+// findValidKey 主体とテナントが一致する有効期限内のキーを取得します。期限切れのキーは削除して nil を返します
+func (m Idempotency) findValidKey(r *http.Request, client *ent.Client, scope idempotencyScope) (*ent.IdempotencyKey, error) {
+	ctx := r.Context()
+
+	stored, err := client.IdempotencyKey.Query().
+		Where(
+			idempotencykey.PrincipalID(scope.principalID),
+			idempotencykey.TenantID(scope.tenantID),
+			idempotencykey.Key(scope.key),
+		).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	if !stored.ExpiresAt.After(m.timer.Now()) {
+		if err := client.IdempotencyKey.DeleteOneID(stored.ID).Exec(ctx); err != nil && !ent.IsNotFound(err) {
+			return nil, eris.Wrap(err, "")
+		}
+		return nil, nil
+	}
+	return stored, nil
+}
+
+// replay 保存済みのレスポンスを返します
+func (m Idempotency) replay(w http.ResponseWriter, stored *ent.IdempotencyKey, requestHash string) {
+	if stored.RequestHash != requestHash {
+		writeIdempotencyError(w, http.StatusUnprocessableEntity, "Idempotency-Key has already been used with a different request")
+		return
+	}
+	if stored.StatusCode == 0 {
+		writeIdempotencyError(w, http.StatusConflict, "a request with the same Idempotency-Key is being processed")
+		return
+	}
+
+	// 保存済みの値で置き換える（同じ名前のヘッダが重複しないようにする）
+	for name, values := range stored.ResponseHeader {
+		w.Header().Del(name)
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	w.Write(stored.ResponseBody)
+}
+
+// storableHeader 保存するレスポンスヘッダを返します。相関IDのヘッダは再生するリクエストのものを返すため除きます
+func storableHeader(header http.Header) http.Header {
+	stored := header.Clone()
+	for _, name := range correlationHeaders {
+		stored.Del(name)
+	}
+	return stored
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// hashRequest メソッド・パス・ボディからリクエストのハッシュを算出します
+func hashRequest(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return "", eris.Wrap(err, "")
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(body))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":      status,
+		"errorCode": types.CodeFromHTTPStatus(status),
+		"message":   message,
+	})
+}
@@ -0,0 +1,227 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/middleware"
+	"github.com/t-kuni/cqrs-example/testUtil"
+)
+
+func TestIdempotency(t *testing.T) {
+	userID := uuid.MustParse("8d9b2f0e-5b8c-4f8e-9d3a-1c2b3a4d5e6f")
+	otherUserID := uuid.MustParse("2f6e1c3b-7a8d-4e9f-8b1a-0c9d8e7f6a5b")
+	tenantID := uuid.MustParse("b3a1c2d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d")
+
+	newRequestBy := func(principalID uuid.UUID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		ctx := auth.WithPrincipal(req.Context(), &auth.Principal{UserID: principalID, TenantIDs: []uuid.UUID{tenantID}})
+		return req.WithContext(tenancy.WithTenantID(ctx, tenantID))
+	}
+	newRequest := func(body string) *http.Request {
+		return newRequestBy(userID, body)
+	}
+
+	t.Run("同じキーで再送された場合は保存済みのレスポンスを返すこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":"1"}`))
+		}))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, newRequest(`{"name":"a"}`))
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, newRequest(`{"name":"a"}`))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, `{"id":"1"}`, second.Body.String())
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.Equal(t, "true", second.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("再生したレスポンスの相関IDのヘッダは再送したリクエストのものだけになること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":"1"}`))
+		}))
+		// 外側の RequestID と同じく、ハンドラの前にリクエストごとの相関IDを設定する
+		withRequestID := func(requestID string, traceparent string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			rec.Header().Set(middleware.RequestIDHeader, requestID)
+			rec.Header().Set(middleware.TraceparentHeader, traceparent)
+			handler.ServeHTTP(rec, newRequest(`{"name":"a"}`))
+			return rec
+		}
+
+		withRequestID("req-1", "00-11111111111111111111111111111111-1111111111111111-01")
+		second := withRequestID("req-2", "00-22222222222222222222222222222222-2222222222222222-01")
+
+		assert.Equal(t, "true", second.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, []string{"req-2"}, second.Header().Values(middleware.RequestIDHeader))
+		assert.Equal(t, []string{"00-22222222222222222222222222222222-2222222222222222-01"}, second.Header().Values(middleware.TraceparentHeader))
+		assert.Equal(t, []string{"application/json"}, second.Header().Values("Content-Type"))
+	})
+
+	t.Run("同じキーで異なるボディが送信された場合は422を返すこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"name":"b"}`))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("サーバエラーの場合はレスポンスを保存せず再送時に再実行すること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("他の主体が同じキーで送信した場合は再生しないこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequestBy(otherUserID, `{"name":"a"}`))
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, rec.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("未認証のリクエストには再生しないこと", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+		unauthenticated := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"a"}`))
+		unauthenticated.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, unauthenticated)
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, rec.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("ハンドラがパニックした場合はキーを解放すること", func(t *testing.T) {
+		cont := testUtil.Prepare(t)
+		defer cont.Finish()
+
+		cont.SetTime("2025-01-02T03:04:05Z")
+
+		var testee *middleware.Idempotency
+		cont.Exec(func(m *middleware.Idempotency) {
+			testee = m
+		})
+
+		calls := 0
+		handler := testee.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		assert.Panics(t, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"name":"a"}`))
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"name":"a"}`))
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	openapiMiddleware "github.com/go-openapi/runtime/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsPath メトリクスを公開するパス
+const MetricsPath = "/metrics"
+
+// unmatchedRoute ルーティングされなかったリクエストのルートのラベル（404 などでラベルの種類が増えないようにする）
+const unmatchedRoute = "unmatched"
+
+type routeRecorderKey struct{}
+
+// routeRecorder ルーティング後に判明したパスのパターンを、ルーティング前のミドルウェアに受け渡します
+type routeRecorder struct {
+	pattern string
+}
+
+func withRouteRecorder(ctx context.Context) (context.Context, *routeRecorder) {
+	recorder := &routeRecorder{}
+	return context.WithValue(ctx, routeRecorderKey{}, recorder), recorder
+}
+
+func (r *routeRecorder) route() string {
+	if r.pattern == "" {
+		return unmatchedRoute
+	}
+	return r.pattern
+}
+
+// RecordRoute マッチしたパスのパターン（/products/{id} など）をメトリクスのラベルとして記録するミドルウェア
+// ルーティング後に実行される setupMiddlewares で使用します
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if recorder, ok := r.Context().Value(routeRecorderKey{}).(*routeRecorder); ok {
+			if route := openapiMiddleware.MatchedRouteFrom(r); route != nil {
+				recorder.pattern = route.PathPattern
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MetricsEndpoint /metrics で Prometheus 形式のメトリクスを返すミドルウェア
+// スクレイプがアクセスログやトレースに残らないよう、最も外側に配置します
+type MetricsEndpoint struct {
+	handler http.Handler
+}
+
+func NewMetricsEndpoint(registry *prometheus.Registry) (*MetricsEndpoint, error) {
+	return &MetricsEndpoint{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}, nil
+}
+
+func (m MetricsEndpoint) MetricsEndpoint(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == MetricsPath && r.Method == http.MethodGet {
+			m.handler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
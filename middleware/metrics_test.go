@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/runtime"
+	openapiMiddleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/runtime/middleware/untyped"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/middleware"
+)
+
+const metricsTestSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "metrics test", "version": "1.0.0"},
+  "produces": ["application/json"],
+  "paths": {
+    "/products/{id}": {
+      "get": {
+        "operationId": "getProduct",
+        "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+// newRoutedHandler は swagger の定義でルーティングし、ルーティング後に RecordRoute を実行するハンドラを返します
+func newRoutedHandler(t *testing.T) http.Handler {
+	spec, err := loads.Analyzed(json.RawMessage(metricsTestSpec), "")
+	assert.NoError(t, err)
+
+	api := untyped.NewAPI(spec)
+	api.RegisterProducer("application/json", runtime.JSONProducer())
+	api.RegisterOperation("get", "/products/{id}", runtime.OperationHandlerFunc(func(params interface{}) (interface{}, error) {
+		return map[string]string{}, nil
+	}))
+
+	return openapiMiddleware.ServeWithBuilder(spec, api, middleware.RecordRoute)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("ルーティングされたパスのパターンとステータスごとにリクエストを記録すること", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		metrics, err := system.NewMetrics(registry)
+		assert.NoError(t, err)
+		logger, _ := system.NewTestLogger()
+		accessLog, err := middleware.NewAccessLog(logger, metrics)
+		assert.NoError(t, err)
+		handler := accessLog.AccessLog(newRoutedHandler(t))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/b3c9e1a4-0000-4000-8000-000000000001", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/b3c9e1a4-0000-4000-8000-000000000002", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/path", nil))
+
+		expected := `
+# HELP http_requests_total Number of HTTP requests by method, route and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/products/{id}",status="200"} 2
+http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"))
+	})
+
+	t.Run("/metricsでPrometheus形式のメトリクスを返すこと", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		metrics, err := system.NewMetrics(registry)
+		assert.NoError(t, err)
+		metrics.IncProjectionIndexed()
+
+		endpoint, err := middleware.NewMetricsEndpoint(registry)
+		assert.NoError(t, err)
+		nextCalled := false
+		handler := endpoint.MetricsEndpoint(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nextCalled = true
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body, _ := io.ReadAll(rec.Body)
+		assert.Contains(t, string(body), "projection_documents_indexed_total 1")
+		assert.False(t, nextCalled)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products", nil))
+		assert.True(t, nextCalled)
+	})
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	customErrors "github.com/t-kuni/cqrs-example/errors"
 	"net/http"
 	"runtime"
 )
@@ -32,14 +33,8 @@ func (m Recover) Recover(next http.Handler) http.Handler {
 			if err := recover(); err != nil {
 				m.WritePanicLog(r, err)
 
-				//shouldResponse := !c.Response().Committed
-				//if shouldResponse {
-				//	err := c.NoContent(http.StatusInternalServerError)
-				//	if err != nil {
-				//		logger.Error(c, err, nil)
-				//	}
-				//}
-				w.WriteHeader(http.StatusInternalServerError)
+				// Accept ヘッダに応じて JSON または problem+json で 500 を返す
+				customErrors.WriteInternalError(w, r)
 			}
 		}()
 		next.ServeHTTP(w, r)
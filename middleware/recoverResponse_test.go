@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/errors"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/middleware"
+)
+
+func TestRecoverResponse(t *testing.T) {
+	panicHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("テストpanicです")
+	})
+
+	t.Run("パニックが発生した場合500のJSONを返しエラーログが出力されること", func(t *testing.T) {
+		logger, loggerHook := system.NewTestLogger()
+		testee, err := middleware.NewRecover(logger)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		testee.Recover(panicHandler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, float64(500), body["code"])
+		assert.Equal(t, "INTERNAL_ERROR", body["errorCode"])
+		assert.Equal(t, "internal server error", body["message"])
+		assert.Equal(t, 1, len(loggerHook.Entries))
+		assert.Equal(t, true, loggerHook.LastEntry().Data["panic"])
+	})
+
+	t.Run("problem+jsonが要求された場合はproblem detailsを返すこと", func(t *testing.T) {
+		logger, _ := system.NewTestLogger()
+		testee, err := middleware.NewRecover(logger)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("Accept", "application/problem+json")
+		rec := httptest.NewRecorder()
+		testee.Recover(panicHandler).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, errors.ProblemJSONMediaType, rec.Header().Get("Content-Type"))
+
+		var problem errors.ProblemDetails
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, "/problems/internal-error", problem.Type)
+		assert.Equal(t, "Internal Server Error", problem.Title)
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Equal(t, "/products", problem.Instance)
+	})
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader リクエストIDを受け取り、レスポンスで返すヘッダ
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader W3C Trace Context のトレースを受け取り、レスポンスで返すヘッダ
+	TraceparentHeader = "traceparent"
+)
+
+// RequestID リクエストIDとトレースコンテキストをコンテキストに設定し、レスポンスヘッダで返すミドルウェア
+// X-Request-ID・traceparent ヘッダが妥当な場合はそれを引き継ぎ、無い場合は生成します
+// Recover や AccessLog のログにも出力されるよう、最も外側に配置します
+type RequestID struct {
+	uuidGenerator system.IUuidGenerator
+}
+
+func NewRequestID(uuidGenerator system.IUuidGenerator) (*RequestID, error) {
+	return &RequestID{
+		uuidGenerator: uuidGenerator,
+	}, nil
+}
+
+func (m RequestID) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !correlation.IsValidRequestID(requestID) {
+			generated, err := m.uuidGenerator.Generate()
+			if err != nil {
+				generated = correlation.NewTraceContext(nil).SpanID
+			}
+			requestID = generated
+		}
+
+		traceContext := newTraceContext(r)
+
+		w.Header().Set(RequestIDHeader, requestID)
+		w.Header().Set(TraceparentHeader, traceContext.Traceparent())
+
+		ctx := correlation.WithRequestID(r.Context(), requestID)
+		ctx = correlation.WithTraceContext(ctx, traceContext)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// newTraceContext は リクエストのトレースコンテキストを生成します
+// Tracing がスパンを開始している場合は、ログとレスポンスヘッダのトレースをそのスパンに揃えます
+func newTraceContext(r *http.Request) correlation.TraceContext {
+	parent, hasParent := correlation.ParseTraceparent(r.Header.Get(TraceparentHeader))
+
+	if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+		traceContext := correlation.TraceContext{
+			TraceID: sc.TraceID().String(),
+			SpanID:  sc.SpanID().String(),
+			Flags:   sc.TraceFlags().String(),
+		}
+		if hasParent && parent.TraceID == traceContext.TraceID {
+			traceContext.ParentSpanID = parent.SpanID
+		}
+		return traceContext
+	}
+
+	if hasParent {
+		return correlation.NewTraceContext(&parent)
+	}
+	return correlation.NewTraceContext(nil)
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	newTestee := func(t *testing.T) *middleware.RequestID {
+		testee, err := middleware.NewRequestID(system.NewUuidGenerator())
+		assert.NoError(t, err)
+		return testee
+	}
+
+	t.Run("ヘッダが無い場合はリクエストIDとトレースを生成してレスポンスヘッダで返すこと", func(t *testing.T) {
+		var gotRequestID string
+		var gotTrace correlation.TraceContext
+		handler := newTestee(t).RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotRequestID = correlation.RequestIDFrom(r.Context())
+			gotTrace, _ = correlation.TraceContextFrom(r.Context())
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		assert.NotEmpty(t, gotRequestID)
+		assert.Equal(t, gotRequestID, rec.Header().Get("X-Request-ID"))
+		assert.Len(t, gotTrace.TraceID, 32)
+		assert.Empty(t, gotTrace.ParentSpanID)
+		assert.Equal(t, gotTrace.Traceparent(), rec.Header().Get("traceparent"))
+	})
+
+	t.Run("ヘッダがある場合はリクエストIDとトレースを引き継ぐこと", func(t *testing.T) {
+		var gotRequestID string
+		var gotTrace correlation.TraceContext
+		handler := newTestee(t).RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotRequestID = correlation.RequestIDFrom(r.Context())
+			gotTrace, _ = correlation.TraceContextFrom(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("X-Request-ID", "req-123")
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "req-123", gotRequestID)
+		assert.Equal(t, "req-123", rec.Header().Get("X-Request-ID"))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", gotTrace.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", gotTrace.ParentSpanID)
+		assert.NotEqual(t, "00f067aa0ba902b7", gotTrace.SpanID)
+		assert.Equal(t, "01", gotTrace.Flags)
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+gotTrace.SpanID+"-01", rec.Header().Get("traceparent"))
+	})
+
+	t.Run("不正なリクエストIDは破棄して新しく生成すること", func(t *testing.T) {
+		var gotRequestID string
+		handler := newTestee(t).RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotRequestID = correlation.RequestIDFrom(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("X-Request-ID", "bad id\r\ninjected")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.NotEqual(t, "bad id\r\ninjected", gotRequestID)
+		assert.NotEmpty(t, gotRequestID)
+	})
+}
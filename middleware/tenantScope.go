@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/t-kuni/cqrs-example/domain/auth"
+	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/domain/tenancy"
+	"github.com/t-kuni/cqrs-example/errors/types"
+)
+
+// TenantIDHeader リクエストの処理対象のテナントを指定するヘッダ
+const TenantIDHeader = "X-Tenant-ID"
+
+// TenantScope リクエストの処理対象のテナントを解決してコンテキストに設定するミドルウェア
+// 認証済みの場合は X-Tenant-ID ヘッダのテナントが主体の tenant_ids に含まれることを確認し、
+// ヘッダが無ければ主体のテナントが1つの場合にそのテナントを処理対象とします
+// テナントを解決できなかった場合も、テナントの範囲に制限されたリクエストとして扱います（product の操作は拒否されます）
+type TenantScope struct {
+	logger system.ILogger
+}
+
+func NewTenantScope(logger system.ILogger) (*TenantScope, error) {
+	return &TenantScope{
+		logger: logger,
+	}, nil
+}
+
+func (m TenantScope) TenantScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tenancy.WithUnresolvedTenant(r.Context())
+		principal, authenticated := auth.FromContext(r.Context())
+
+		if header := r.Header.Get(TenantIDHeader); header != "" {
+			tenantID, err := uuid.Parse(header)
+			if err != nil {
+				m.logger.Warn(r, "invalid tenant id", map[string]interface{}{
+					"tenantId": header,
+				})
+				writeTenantScopeError(w, http.StatusBadRequest, TenantIDHeader+" must be a UUID")
+				return
+			}
+			if authenticated && !principal.HasTenant(tenantID) {
+				m.logger.Warn(r, "tenant is not accessible", map[string]interface{}{
+					"tenantId": header,
+				})
+				writeTenantScopeError(w, http.StatusForbidden, "tenant is not accessible")
+				return
+			}
+			ctx = tenancy.WithTenantID(r.Context(), tenantID)
+		} else if authenticated && len(principal.TenantIDs) == 1 {
+			ctx = tenancy.WithTenantID(r.Context(), principal.TenantIDs[0])
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func writeTenantScopeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":      status,
+		"errorCode": types.CodeFromHTTPStatus(status),
+		"message":   message,
+	})
+}
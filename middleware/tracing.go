@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/t-kuni/cqrs-example/middleware"
+
+// Tracing 受信したリクエストごとに OpenTelemetry のサーバスパンを開始するミドルウェア
+// traceparent ヘッダがある場合は上流のトレースを引き継ぎます
+// RequestID がスパンのトレースIDをログとレスポンスヘッダに使用するよう、RequestID より外側に配置します
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracing(tp trace.TracerProvider) (*Tracing, error) {
+	return &Tracing{
+		tracer:     tp.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}, nil
+}
+
+func (m Tracing) Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := m.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := m.tracer.Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		respWriter := NewCustomResponseWriter(w)
+		next.ServeHTTP(respWriter, r.WithContext(ctx))
+
+		status := respWriter.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/correlation"
+	"github.com/t-kuni/cqrs-example/infrastructure/system"
+	"github.com/t-kuni/cqrs-example/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	newTestee := func(t *testing.T) (http.Handler, *tracetest.SpanRecorder, *correlation.TraceContext) {
+		recorder := tracetest.NewSpanRecorder()
+		tracing, err := middleware.NewTracing(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		assert.NoError(t, err)
+		requestID, err := middleware.NewRequestID(system.NewUuidGenerator())
+		assert.NoError(t, err)
+
+		gotTrace := &correlation.TraceContext{}
+		handler := tracing.Tracing(requestID.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*gotTrace, _ = correlation.TraceContextFrom(r.Context())
+			if r.URL.Path == "/error" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})))
+		return handler, recorder, gotTrace
+	}
+
+	t.Run("リクエストごとにサーバスパンを記録しログのトレースをスパンに揃えること", func(t *testing.T) {
+		handler, recorder, gotTrace := newTestee(t)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "HTTP GET", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Contains(t, span.Attributes(), attribute.String("url.path", "/products"))
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+		assert.Equal(t, span.SpanContext().TraceID().String(), gotTrace.TraceID)
+		assert.Equal(t, span.SpanContext().SpanID().String(), gotTrace.SpanID)
+		assert.Equal(t, gotTrace.Traceparent(), rec.Header().Get("traceparent"))
+	})
+
+	t.Run("traceparentヘッダがある場合は上流のトレースを引き継ぐこと", func(t *testing.T) {
+		handler, recorder, gotTrace := newTestee(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		span := recorder.Ended()[0]
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", gotTrace.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", gotTrace.ParentSpanID)
+	})
+
+	t.Run("5xxを返した場合はスパンをエラーにすること", func(t *testing.T) {
+		handler, recorder, _ := newTestee(t)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/error", nil))
+
+		span := recorder.Ended()[0]
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	})
+}
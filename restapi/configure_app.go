@@ -5,20 +5,24 @@ package restapi
 import (
 	"context"
 	"crypto/tls"
-	"github.com/joho/godotenv"
-	// useCaseCompanies "github.com/t-kuni/cqrs-example/application/handler"
+	"github.com/t-kuni/cqrs-example/application/handler"
 	"github.com/t-kuni/cqrs-example/di"
+	"github.com/t-kuni/cqrs-example/domain/auth"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
 	middleware2 "github.com/t-kuni/cqrs-example/middleware"
-	// "github.com/t-kuni/cqrs-example/restapi/operations/companies"
-	// "github.com/t-kuni/cqrs-example/restapi/operations/todos"
-	// "github.com/t-kuni/cqrs-example/restapi/operations/user"
+	"github.com/t-kuni/cqrs-example/restapi/operations/categories"
+	"github.com/t-kuni/cqrs-example/restapi/operations/products"
+	"github.com/t-kuni/cqrs-example/restapi/operations/tenants"
+	"github.com/t-kuni/cqrs-example/restapi/operations/users"
 	"go.uber.org/fx"
 	"log"
 	"net/http"
 	"os"
 
+	"github.com/go-openapi/errors"
 	"github.com/go-openapi/runtime"
+	openapiMiddleware "github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/runtime/security"
 	"github.com/t-kuni/cqrs-example/restapi/operations"
 )
 
@@ -30,6 +34,14 @@ var app *fx.App
 var middlewares struct {
 	recoverHandler middleware
 	accessLog      middleware
+	idempotency    middleware
+	tenantScope    middleware
+	authentication middleware
+	requestID      middleware
+	tracing        middleware
+	metrics        middleware
+	healthCheck    middleware
+	drain          middleware
 }
 
 func configureFlags(api *operations.AppAPI) {
@@ -37,8 +49,6 @@ func configureFlags(api *operations.AppAPI) {
 }
 
 func configureAPI(api *operations.AppAPI) http.Handler {
-	godotenv.Load()
-
 	// Set your custom logger if needed. Default one is log.Printf
 	// Expected interface func(string, ...interface{})
 	//
@@ -54,27 +64,93 @@ func configureAPI(api *operations.AppAPI) http.Handler {
 	api.JSONProducer = runtime.JSONProducer()
 
 	ctx := context.Background()
+	var logger system.ILogger
 	app = di.NewApp(fx.Invoke(func(
+		metricsEndpoint *middleware2.MetricsEndpoint,
+		healthCheck *middleware2.HealthCheck,
+		drain *middleware2.Drain,
+		tracing *middleware2.Tracing,
+		requestID *middleware2.RequestID,
 		recoverHandler *middleware2.Recover,
 		accessLog *middleware2.AccessLog,
-		logger system.ILogger,
+		idempotency *middleware2.Idempotency,
+		tenantScope *middleware2.TenantScope,
+		authentication *middleware2.Authentication,
+		l system.ILogger,
 		customServeError func(http.ResponseWriter, *http.Request, error),
 
-		// listTodos *useCaseCompanies.ListTodos,
-		// getCompanies *useCaseCompanies.GetCompanies,
-		// getCompaniesUsers *useCaseCompanies.GetCompaniesUsers,
-		// getUsers *useCaseCompanies.GetUsers,
-		// postUser *useCaseCompanies.PostUser,
+		getUsers *handler.GetUsers,
+		getUsersID *handler.GetUsersID,
+		postUsers *handler.PostUsers,
+		putUsers *handler.PutUsers,
+		deleteUsers *handler.DeleteUsers,
+		getTenants *handler.GetTenants,
+		getTenantsID *handler.GetTenantsID,
+		postTenants *handler.PostTenants,
+		putTenants *handler.PutTenants,
+		deleteTenants *handler.DeleteTenants,
+		getCategories *handler.GetCategories,
+		getCategoriesID *handler.GetCategoriesID,
+		postCategories *handler.PostCategories,
+		putCategories *handler.PutCategories,
+		deleteCategories *handler.DeleteCategories,
+		getProducts *handler.GetProducts,
+		postProducts *handler.PostProducts,
+		putProducts *handler.PutProducts,
+		deleteProducts *handler.DeleteProducts,
 	) {
+		logger = l
 		api.ServeError = customServeError
+		middlewares.metrics = metricsEndpoint.MetricsEndpoint
+		middlewares.healthCheck = healthCheck.HealthCheck
+		middlewares.drain = drain.Drain
+
+		// リスナを閉じる前に readiness を down にして新しいリクエストが振り分けられないようにし、処理中のリクエストの完了を待つ
+		api.PreServerShutdown = drain.BeginShutdown
+		middlewares.tracing = tracing.Tracing
+		middlewares.requestID = requestID.RequestID
 		middlewares.recoverHandler = recoverHandler.Recover
 		middlewares.accessLog = accessLog.AccessLog
-
-		// api.TodosGetTodosHandler = todos.GetTodosHandlerFunc(listTodos.Main)
-		// api.CompaniesGetCompaniesHandler = companies.GetCompaniesHandlerFunc(getCompanies.Main)
-		// api.CompaniesGetCompaniesUsersHandler = companies.GetCompaniesUsersHandlerFunc(getCompaniesUsers.Main)
-		// api.UserGetUsersHandler = user.GetUsersHandlerFunc(getUsers.Main)
-		// api.UserPostUsersHandler = user.PostUsersHandlerFunc(postUser.Main)
+		middlewares.idempotency = idempotency.Idempotency
+		middlewares.tenantScope = tenantScope.TenantScope
+		middlewares.authentication = authentication.Authentication
+
+		// swagger の Bearer セキュリティ定義の認証処理（認証できない場合は 401 を返す）
+		// トークンは Authentication ミドルウェアで検証済みのため、再度検証せずにコンテキストの主体を使用する
+		// BearerAuth にはリクエストが渡されないため、リクエストを受け取る認証処理に置き換える
+		api.APIKeyAuthenticator = func(_ string, _ string, _ func(string) (interface{}, error)) runtime.Authenticator {
+			return security.HttpAuthenticator(func(r *http.Request) (bool, interface{}, error) {
+				principal, ok := auth.FromContext(r.Context())
+				if !ok {
+					return true, nil, errors.Unauthenticated("Bearer")
+				}
+				return true, principal, nil
+			})
+		}
+		// APIKeyAuthenticator を置き換えたため呼び出されないが、生成されたAPIの検証で必須のため設定する
+		api.BearerAuth = func(string) (interface{}, error) {
+			return nil, errors.Unauthenticated("Bearer")
+		}
+
+		api.UsersGetUsersHandler = users.GetUsersHandlerFunc(withoutPrincipal(getUsers.Main))
+		api.UsersGetUsersIDHandler = users.GetUsersIDHandlerFunc(withoutPrincipal(getUsersID.Main))
+		api.UsersPostUsersHandler = users.PostUsersHandlerFunc(withoutPrincipal(postUsers.Main))
+		api.UsersPutUsersHandler = users.PutUsersHandlerFunc(withoutPrincipal(putUsers.Main))
+		api.UsersDeleteUsersHandler = users.DeleteUsersHandlerFunc(withoutPrincipal(deleteUsers.Main))
+		api.TenantsGetTenantsHandler = tenants.GetTenantsHandlerFunc(withoutPrincipal(getTenants.Main))
+		api.TenantsGetTenantsIDHandler = tenants.GetTenantsIDHandlerFunc(withoutPrincipal(getTenantsID.Main))
+		api.TenantsPostTenantsHandler = tenants.PostTenantsHandlerFunc(withoutPrincipal(postTenants.Main))
+		api.TenantsPutTenantsHandler = tenants.PutTenantsHandlerFunc(withoutPrincipal(putTenants.Main))
+		api.TenantsDeleteTenantsHandler = tenants.DeleteTenantsHandlerFunc(withoutPrincipal(deleteTenants.Main))
+		api.CategoriesGetCategoriesHandler = categories.GetCategoriesHandlerFunc(withoutPrincipal(getCategories.Main))
+		api.CategoriesGetCategoriesIDHandler = categories.GetCategoriesIDHandlerFunc(withoutPrincipal(getCategoriesID.Main))
+		api.CategoriesPostCategoriesHandler = categories.PostCategoriesHandlerFunc(withoutPrincipal(postCategories.Main))
+		api.CategoriesPutCategoriesHandler = categories.PutCategoriesHandlerFunc(withoutPrincipal(putCategories.Main))
+		api.CategoriesDeleteCategoriesHandler = categories.DeleteCategoriesHandlerFunc(withoutPrincipal(deleteCategories.Main))
+		api.ProductsGetProductsHandler = products.GetProductsHandlerFunc(withoutPrincipal(getProducts.Main))
+		api.ProductsPostProductsHandler = products.PostProductsHandlerFunc(withoutPrincipal(postProducts.Main))
+		api.ProductsPutProductsHandler = products.PutProductsHandlerFunc(withoutPrincipal(putProducts.Main))
+		api.ProductsDeleteProductsHandler = products.DeleteProductsHandlerFunc(withoutPrincipal(deleteProducts.Main))
 	}))
 	err := app.Start(ctx)
 	if err != nil {
@@ -82,15 +158,27 @@ func configureAPI(api *operations.AppAPI) http.Handler {
 		os.Exit(1)
 	}
 
-	api.PreServerShutdown = func() {}
-
+	// リクエストの処理が終わった後にDB接続などを閉じ、最後にログを書き出す
 	api.ServerShutdown = func() {
-		app.Stop(ctx)
+		stopCtx, cancel := context.WithTimeout(ctx, app.StopTimeout())
+		defer cancel()
+		if err := app.Stop(stopCtx); err != nil {
+			logger.WarnWithError(nil, err, nil)
+		}
+		logger.Flush()
 	}
 
 	return setupGlobalMiddleware(api.Serve(setupMiddlewares))
 }
 
+// withoutPrincipal は 認証済みの主体を受け取らないハンドラを、セキュリティ定義のある操作のハンドラに変換します
+// ハンドラは主体を auth.FromContext(params.HTTPRequest.Context()) から取得します
+func withoutPrincipal[P any](handle func(P) openapiMiddleware.Responder) func(P, interface{}) openapiMiddleware.Responder {
+	return func(params P, _ interface{}) openapiMiddleware.Responder {
+		return handle(params)
+	}
+}
+
 // The TLS configuration before HTTPS server starts.
 func configureTLS(tlsConfig *tls.Config) {
 	// Make all necessary changes to the TLS configuration here.
@@ -106,11 +194,11 @@ func configureServer(s *http.Server, scheme, addr string) {
 // The middleware configuration is for the handler executors. These do not apply to the swagger.json document.
 // The middleware executes after routing but before authentication, binding and validation.
 func setupMiddlewares(handler http.Handler) http.Handler {
-	return handler
+	return middleware2.RecordRoute(handler)
 }
 
 // The middleware configuration happens before anything, this middleware also applies to serving the swagger.json document.
 // So this is a good place to plug in a panic handling middleware, logging and metrics.
 func setupGlobalMiddleware(handler http.Handler) http.Handler {
-	return middlewares.recoverHandler(middlewares.accessLog(handler))
+	return middlewares.healthCheck(middlewares.drain(middlewares.metrics(middlewares.tracing(middlewares.requestID(middlewares.recoverHandler(middlewares.authentication(middlewares.accessLog(middlewares.tenantScope(middlewares.idempotency(handler))))))))))
 }
@@ -0,0 +1,214 @@
+// Package datagen は seed-v2 で投入する負荷試験用の合成データを、設定に従って再現可能に生成します
+package datagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/rotisserie/eris"
+	"github.com/t-kuni/cqrs-example/config"
+)
+
+const (
+	// DistributionEven は 順番に均等に割り当てる分布です
+	DistributionEven = "even"
+	// DistributionUniform は 無作為に割り当てる一様分布です
+	DistributionUniform = "uniform"
+	// DistributionZipf は 一部（先頭のテナントなど）に偏って割り当てる Zipf 分布です
+	DistributionZipf = "zipf"
+)
+
+// Config は 生成するデータの件数と値の分布の設定です（YAML のキーは json タグの名前です）
+// 省略した項目は DefaultConfig の値になります。0 を指定した件数は省略とは区別し、そのテーブルには投入しません
+type Config struct {
+	// Seed は 乱数のシードです。同じ設定とシードからは同じデータ（ID を含む）を生成します
+	// 0 の場合は実行ごとに異なるシードを使用します
+	Seed   int64  `json:"seed"`
+	Counts Counts `json:"counts"`
+	// ProductTenants と ProductCategories は product をテナントとカテゴリに割り当てる分布です
+	ProductTenants    Distribution `json:"product_tenants"`
+	ProductCategories Distribution `json:"product_categories"`
+	Price             IntRange     `json:"price"`
+	// Sizes と Colors は properties の size / color の値と、その値を選ぶ重みです
+	Sizes    map[string]float64 `json:"sizes"`
+	Colors   map[string]float64 `json:"colors"`
+	ListedAt TimeRange          `json:"listed_at"`
+}
+
+// Counts は テーブルごとの生成する行数です
+type Counts struct {
+	Users      int `json:"users"`
+	Tenants    int `json:"tenants"`
+	Categories int `json:"categories"`
+	Products   int `json:"products"`
+}
+
+// Distribution は 割り当て先を選ぶ分布です
+type Distribution struct {
+	// Type は even / uniform / zipf のいずれかです
+	Type string `json:"type"`
+	// S と V は zipf のパラメータです（math/rand の NewZipf）
+	// S（1 より大きい）が大きいほど先頭に集中し、V（1 以上）が大きいほど先頭の偏りが緩やかになります
+	S float64 `json:"s"`
+	V float64 `json:"v"`
+}
+
+// IntRange は Min 以上 Max 以下の範囲です
+type IntRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// TimeRange は From 以上 To 未満の範囲です
+// 省略した場合は実行時点から過去1年間になるため、再現可能なデータを生成する場合は両方を指定してください
+type TimeRange struct {
+	From *Time `json:"from"`
+	To   *Time `json:"to"`
+}
+
+// Time は 2006-01-02 または RFC3339 形式で指定できる日時です
+type Time struct {
+	time.Time
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return eris.Wrap(err, "")
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return eris.Errorf("invalid time %q (expected 2006-01-02 or RFC3339)", s)
+}
+
+// DefaultConfig は 既定の設定（ユーザ200・テナント1000・カテゴリ50・商品100万件、均等な割り当て）を返します
+func DefaultConfig() Config {
+	return Config{
+		Counts: Counts{
+			Users:      200,
+			Tenants:    1000,
+			Categories: 50,
+			Products:   1000000,
+		},
+		ProductTenants:    Distribution{Type: DistributionEven},
+		ProductCategories: Distribution{Type: DistributionEven},
+		Price:             IntRange{Min: 100, Max: 10000},
+		Sizes:             map[string]float64{"S": 1, "M": 1, "L": 1},
+		Colors:            map[string]float64{"red": 1, "green": 1, "blue": 1},
+	}
+}
+
+// LoadConfig は YAML ファイルから設定を読み込み、省略した項目を既定値で補って検証します
+func LoadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, eris.Wrap(err, "")
+	}
+
+	// 0 を指定した項目と省略した項目を区別するため、既定の設定に YAML で指定した項目を上書きする
+	// map は既定の値と混ざらないよう、空にしてから読み込んで applyDefaults で補う
+	cfg := DefaultConfig()
+	cfg.Sizes = nil
+	cfg.Colors = nil
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return Config{}, eris.Wrapf(err, "failed to parse %s", path)
+	}
+	cfg.applyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// applyDefaults は 省略された（空の）map の項目を DefaultConfig の値にします
+func (c *Config) applyDefaults() {
+	d := DefaultConfig()
+	if len(c.Sizes) == 0 {
+		c.Sizes = d.Sizes
+	}
+	if len(c.Colors) == 0 {
+		c.Colors = d.Colors
+	}
+}
+
+// Validate は 設定を検証し、不正な項目がある場合はその全てを列挙した config.ValidationError を返します
+func (c Config) Validate() error {
+	var problems []string
+	addProblem := func(key string, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	for key, count := range map[string]int{
+		"counts.users":      c.Counts.Users,
+		"counts.tenants":    c.Counts.Tenants,
+		"counts.categories": c.Counts.Categories,
+		"counts.products":   c.Counts.Products,
+	} {
+		if count < 0 {
+			addProblem(key, "must be a non-negative integer (got %d)", count)
+		}
+	}
+	// tenant の owner と product の割り当て先が必要になる
+	if c.Counts.Tenants > 0 && c.Counts.Users == 0 {
+		addProblem("counts.users", "must be positive when counts.tenants is positive")
+	}
+	if c.Counts.Products > 0 && c.Counts.Tenants == 0 {
+		addProblem("counts.tenants", "must be positive when counts.products is positive")
+	}
+	if c.Counts.Products > 0 && c.Counts.Categories == 0 {
+		addProblem("counts.categories", "must be positive when counts.products is positive")
+	}
+
+	for key, d := range map[string]Distribution{"product_tenants": c.ProductTenants, "product_categories": c.ProductCategories} {
+		switch d.Type {
+		case DistributionEven, DistributionUniform:
+		case DistributionZipf:
+			if d.S <= 1 {
+				addProblem(key+".s", "must be greater than 1 (got %v)", d.S)
+			}
+			if d.V < 1 {
+				addProblem(key+".v", "must be 1 or greater (got %v)", d.V)
+			}
+		default:
+			addProblem(key+".type", "must be one of %s, %s, %s (got %q)", DistributionEven, DistributionUniform, DistributionZipf, d.Type)
+		}
+	}
+
+	if c.Price.Min < 0 || c.Price.Min > c.Price.Max {
+		addProblem("price", "must satisfy 0 <= min <= max (got min=%d, max=%d)", c.Price.Min, c.Price.Max)
+	}
+
+	for key, weights := range map[string]map[string]float64{"sizes": c.Sizes, "colors": c.Colors} {
+		total := 0.0
+		for value, weight := range weights {
+			if weight < 0 {
+				addProblem(key+"."+value, "weight must not be negative (got %v)", weight)
+			}
+			total += weight
+		}
+		if total <= 0 {
+			addProblem(key, "must have at least one value with a positive weight")
+		}
+	}
+
+	if (c.ListedAt.From == nil) != (c.ListedAt.To == nil) {
+		addProblem("listed_at", "from and to must be specified together")
+	} else if c.ListedAt.From != nil && !c.ListedAt.From.Before(c.ListedAt.To.Time) {
+		addProblem("listed_at", "from must be before to")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &config.ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package datagen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
+	"github.com/t-kuni/cqrs-example/seeds/datagen"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "seed-v2.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("リポジトリの設定ファイルを読み込めること", func(t *testing.T) {
+		cfg, err := datagen.LoadConfig("../seed-v2.yml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), cfg.Seed)
+		assert.Equal(t, datagen.DefaultConfig().Counts, cfg.Counts)
+		assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), cfg.ListedAt.From.Time)
+		assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), cfg.ListedAt.To.Time)
+	})
+
+	t.Run("省略した項目は既定値になること", func(t *testing.T) {
+		path := writeConfig(t, `
+counts:
+  products: 5000
+product_tenants:
+  type: zipf
+  s: 1.5
+  v: 2
+sizes:
+  XL: 3
+listed_at:
+  from: 2026-01-01T09:00:00+09:00
+  to: 2026-02-01
+`)
+
+		cfg, err := datagen.LoadConfig(path)
+
+		assert.NoError(t, err)
+		d := datagen.DefaultConfig()
+		assert.Equal(t, datagen.Counts{Users: 200, Tenants: 1000, Categories: 50, Products: 5000}, cfg.Counts)
+		assert.Equal(t, datagen.Distribution{Type: datagen.DistributionZipf, S: 1.5, V: 2}, cfg.ProductTenants)
+		assert.Equal(t, d.ProductCategories, cfg.ProductCategories)
+		assert.Equal(t, d.Price, cfg.Price)
+		assert.Equal(t, map[string]float64{"XL": 3}, cfg.Sizes)
+		assert.Equal(t, d.Colors, cfg.Colors)
+		assert.True(t, cfg.ListedAt.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("0 を指定した件数は既定値にならないこと", func(t *testing.T) {
+		path := writeConfig(t, `
+counts:
+  users: 10
+  tenants: 0
+  categories: 0
+  products: 0
+`)
+
+		cfg, err := datagen.LoadConfig(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, datagen.Counts{Users: 10}, cfg.Counts)
+	})
+
+	t.Run("割り当て先の件数が 0 の場合はエラーになること", func(t *testing.T) {
+		path := writeConfig(t, `
+counts:
+  users: 0
+  categories: 0
+`)
+
+		_, err := datagen.LoadConfig(path)
+
+		var validationErr *config.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, []string{
+				"counts.categories: must be positive when counts.products is positive",
+				"counts.users: must be positive when counts.tenants is positive",
+			}, validationErr.Problems)
+		}
+	})
+
+	t.Run("不正な項目を全て列挙すること", func(t *testing.T) {
+		path := writeConfig(t, `
+counts:
+  users: -1
+product_tenants:
+  type: zipf
+  s: 1
+product_categories:
+  type: normal
+price:
+  min: 500
+  max: 100
+colors:
+  red: -1
+listed_at:
+  from: 2026-01-01
+`)
+
+		_, err := datagen.LoadConfig(path)
+
+		var validationErr *config.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, []string{
+				"colors.red: weight must not be negative (got -1)",
+				"colors: must have at least one value with a positive weight",
+				"counts.users: must be a non-negative integer (got -1)",
+				"listed_at: from and to must be specified together",
+				"price: must satisfy 0 <= min <= max (got min=500, max=100)",
+				`product_categories.type: must be one of even, uniform, zipf (got "normal")`,
+				"product_tenants.s: must be greater than 1 (got 1)",
+				"product_tenants.v: must be 1 or greater (got 0)",
+			}, validationErr.Problems)
+		}
+	})
+
+	t.Run("日時の形式が不正な場合はエラーになること", func(t *testing.T) {
+		path := writeConfig(t, `
+listed_at:
+  from: 2026/01/01
+  to: 2026/02/01
+`)
+
+		_, err := datagen.LoadConfig(path)
+		assert.Error(t, err)
+	})
+}
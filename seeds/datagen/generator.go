@@ -0,0 +1,199 @@
+package datagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/t-kuni/cqrs-example/domain/model"
+)
+
+// Generator は Config に従って各テーブルの行を生成します
+// 全ての値（ID を含む）を1つの乱数生成器から生成するため、同じ設定とシードで同じ順に呼び出すと同じデータになります
+type Generator struct {
+	cfg        Config
+	seed       int64
+	rand       *rand.Rand
+	listedFrom time.Time
+	listedTo   time.Time
+}
+
+// NewGenerator は Generator を生成します。cfg は Validate 済みである必要があります
+func NewGenerator(cfg Config) *Generator {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	listedTo := time.Now()
+	listedFrom := listedTo.AddDate(-1, 0, 0)
+	if cfg.ListedAt.From != nil && cfg.ListedAt.To != nil {
+		listedFrom, listedTo = cfg.ListedAt.From.Time, cfg.ListedAt.To.Time
+	}
+
+	return &Generator{
+		cfg:        cfg,
+		seed:       seed,
+		rand:       rand.New(rand.NewSource(seed)),
+		listedFrom: listedFrom,
+		listedTo:   listedTo,
+	}
+}
+
+// Seed は 使用しているシードを返します（Config.Seed が 0 の場合に同じデータを再生成するために使用します）
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// IDs は count 個の ID を生成します
+func (g *Generator) IDs(count int) []uuid.UUID {
+	ids := make([]uuid.UUID, count)
+	for i := range ids {
+		ids[i] = g.newID()
+	}
+	return ids
+}
+
+func (g *Generator) newID() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(g.rand)
+	if err != nil {
+		// math/rand の Read はエラーを返さない
+		panic(err)
+	}
+	return id
+}
+
+// Users は users の行（id, name）を返します
+func (g *Generator) Users(ids []uuid.UUID) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for i, id := range ids {
+			name := fmt.Sprintf("ユーザ%d", i+1)
+			if !yield([]any{id, name}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Tenants は tenants の行（id, owner_id, name）を返します。テナントは先頭から順にユーザに均等に割り当てます
+func (g *Generator) Tenants(ids []uuid.UUID, userIDs []uuid.UUID) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for i, id := range ids {
+			name := fmt.Sprintf("テナント%d", i+1)
+			ownerID := userIDs[i*len(userIDs)/len(ids)]
+			if !yield([]any{id, ownerID, name}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Categories は categories の行（id, name）を返します
+func (g *Generator) Categories(ids []uuid.UUID) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for i, id := range ids {
+			name := fmt.Sprintf("カテゴリ%d", i+1)
+			if !yield([]any{id, name}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ProductColumns は Products が返す行の列です
+var ProductColumns = []string{"id", "tenant_id", "category_id", "name", "price", "properties", "listed_at"}
+
+// Products は Counts.Products 件の products の行（ProductColumns の順）を返します
+func (g *Generator) Products(tenantIDs, categoryIDs []uuid.UUID) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		pickTenant := g.picker(g.cfg.ProductTenants, len(tenantIDs))
+		pickCategory := g.picker(g.cfg.ProductCategories, len(categoryIDs))
+		pickSize := g.weightedPicker(g.cfg.Sizes)
+		pickColor := g.weightedPicker(g.cfg.Colors)
+		listedSpan := int64(g.listedTo.Sub(g.listedFrom))
+
+		for i := 0; i < g.cfg.Counts.Products; i++ {
+			id := g.newID()
+			name := fmt.Sprintf("商品%d", i+1)
+			price := g.cfg.Price.Min + g.rand.Int63n(g.cfg.Price.Max-g.cfg.Price.Min+1)
+
+			// Properties
+			size := pickSize()
+			latitude := fmt.Sprintf("%.6f", 20.43+g.rand.Float64()*(45.55-20.43))
+			longitude := fmt.Sprintf("%.6f", 122.93+g.rand.Float64()*(153.99-122.93))
+			color := pickColor()
+			properties := &model.ProductProperties{
+				Size:      &size,
+				Latitude:  &latitude,
+				Longitude: &longitude,
+				Color:     &color,
+			}
+
+			// JSON化
+			propertiesJSON, err := json.Marshal(properties)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			listedAt := g.listedFrom.Add(time.Duration(g.rand.Int63n(listedSpan))).Truncate(time.Second)
+
+			tenantID := tenantIDs[pickTenant(i)]
+			categoryID := categoryIDs[pickCategory(i)]
+
+			if !yield([]any{id, tenantID, categoryID, name, price, propertiesJSON, listedAt}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// picker は i 番目の行の割り当て先のインデックス（0 以上 n 未満）を分布に従って返す関数を返します
+// zipf の場合はインデックスが小さいほど多く割り当てます
+func (g *Generator) picker(d Distribution, n int) func(i int) int {
+	switch d.Type {
+	case DistributionUniform:
+		return func(int) int {
+			return g.rand.Intn(n)
+		}
+	case DistributionZipf:
+		zipf := rand.NewZipf(g.rand, d.S, d.V, uint64(n-1))
+		return func(int) int {
+			return int(zipf.Uint64())
+		}
+	default:
+		return func(i int) int {
+			return i % n
+		}
+	}
+}
+
+// weightedPicker は weights の重みに従って値を返す関数を返します
+// 同じシードで同じ値を選ぶように、値は名前順に並べてから累積します
+func (g *Generator) weightedPicker(weights map[string]float64) func() string {
+	values := make([]string, 0, len(weights))
+	for value := range weights {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	cumulative := make([]float64, len(values))
+	total := 0.0
+	for i, value := range values {
+		total += weights[value]
+		cumulative[i] = total
+	}
+
+	return func() string {
+		r := g.rand.Float64() * total
+		i := sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > r })
+		if i == len(values) {
+			i = len(values) - 1
+		}
+		return values[i]
+	}
+}
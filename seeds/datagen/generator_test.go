@@ -0,0 +1,106 @@
+package datagen_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/domain/model"
+	"github.com/t-kuni/cqrs-example/seeds/datagen"
+)
+
+func testConfig() datagen.Config {
+	from := datagen.Time{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	to := datagen.Time{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}
+
+	cfg := datagen.DefaultConfig()
+	cfg.Seed = 42
+	cfg.Counts = datagen.Counts{Users: 2, Tenants: 10, Categories: 3, Products: 2000}
+	cfg.Price = datagen.IntRange{Min: 500, Max: 600}
+	cfg.Sizes = map[string]float64{"S": 1, "M": 0, "L": 3}
+	cfg.ListedAt = datagen.TimeRange{From: &from, To: &to}
+	return cfg
+}
+
+// generate は seed-v2 と同じ順に全ての行を生成します
+func generate(t *testing.T, cfg datagen.Config) (tenants [][]any, products [][]any) {
+	gen := datagen.NewGenerator(cfg)
+	userIDs := gen.IDs(cfg.Counts.Users)
+	tenantIDs := gen.IDs(cfg.Counts.Tenants)
+	categoryIDs := gen.IDs(cfg.Counts.Categories)
+
+	for row, err := range gen.Tenants(tenantIDs, userIDs) {
+		assert.NoError(t, err)
+		tenants = append(tenants, row)
+	}
+	for row, err := range gen.Products(tenantIDs, categoryIDs) {
+		assert.NoError(t, err)
+		products = append(products, row)
+	}
+	return tenants, products
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("同じシードからは同じデータを生成すること", func(t *testing.T) {
+		cfg := testConfig()
+		_, first := generate(t, cfg)
+		_, second := generate(t, cfg)
+
+		assert.Equal(t, first, second)
+
+		cfg.Seed = 43
+		_, other := generate(t, cfg)
+		assert.NotEqual(t, first[0][0], other[0][0])
+	})
+
+	t.Run("設定の範囲と重みに従って値を生成すること", func(t *testing.T) {
+		cfg := testConfig()
+		tenants, products := generate(t, cfg)
+
+		assert.Len(t, tenants, 10)
+		assert.Equal(t, tenants[0][1], tenants[4][1])
+		assert.NotEqual(t, tenants[4][1], tenants[5][1])
+
+		assert.Len(t, products, 2000)
+		sizes := map[string]int{}
+		tenantCounts := map[uuid.UUID]int{}
+		for _, row := range products {
+			price := row[4].(int64)
+			assert.True(t, 500 <= price && price <= 600, "price %d", price)
+
+			var properties model.ProductProperties
+			assert.NoError(t, json.Unmarshal(row[5].([]byte), &properties))
+			sizes[*properties.Size]++
+
+			listedAt := row[6].(time.Time)
+			assert.False(t, listedAt.Before(cfg.ListedAt.From.Time), "listed_at %s", listedAt)
+			assert.True(t, listedAt.Before(cfg.ListedAt.To.Time), "listed_at %s", listedAt)
+
+			tenantCounts[row[1].(uuid.UUID)]++
+		}
+		assert.Zero(t, sizes["M"])
+		assert.Greater(t, sizes["L"], sizes["S"]*2)
+
+		// even の場合は全てのテナントに同じ件数を割り当てる
+		for _, row := range tenants {
+			assert.Equal(t, 200, tenantCounts[row[0].(uuid.UUID)])
+		}
+	})
+
+	t.Run("zipf の場合は先頭のテナントに偏って割り当てること", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.ProductTenants = datagen.Distribution{Type: datagen.DistributionZipf, S: 2, V: 1}
+		tenants, products := generate(t, cfg)
+
+		tenantCounts := map[uuid.UUID]int{}
+		for _, row := range products {
+			tenantCounts[row[1].(uuid.UUID)]++
+		}
+		first := tenantCounts[tenants[0][0].(uuid.UUID)]
+		last := tenantCounts[tenants[len(tenants)-1][0].(uuid.UUID)]
+		assert.Greater(t, first, len(products)/2)
+		assert.Greater(t, first, last*10)
+	})
+}
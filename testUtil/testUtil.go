@@ -8,9 +8,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/t-kuni/cqrs-example/config"
 	"github.com/t-kuni/cqrs-example/di"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/db"
 	"github.com/t-kuni/cqrs-example/domain/infrastructure/system"
@@ -26,9 +26,12 @@ func TestMain(m *testing.M) {
 		panic("Could not get current file path")
 	}
 	directory := filepath.Dir(file)
-	godotenv.Load(filepath.Join(directory, "..", ".env.testing"))
+	cfg, err := config.Load(filepath.Join(directory, "..", ".env.testing"))
+	if err != nil {
+		panic(err)
+	}
 
-	dbImpl.RegisterTxdbDriver()
+	dbImpl.RegisterTxdbDriver(cfg.DB)
 
 	code := m.Run()
 	os.Exit(code)